@@ -0,0 +1,186 @@
+package common
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	asciiStart   = ':'
+	asciiEnd     = "\r\n"
+	asciiMinSize = 9
+	asciiMaxSize = 513
+)
+
+// DefaultASCIICharTimeout ASCII 帧字符间的默认最大间隔
+const DefaultASCIICharTimeout = time.Second
+
+// DefaultASCIIDelimiter ASCII 帧默认的结束符，可通过诊断 (功能码 0x08) 子功能 0x03 修改
+const DefaultASCIIDelimiter byte = '\n'
+
+// ASCIIDelimiterTransport 设备的通信层实现该接口时，服务按返回的结束符拆分发往该设备的 ASCII 帧
+type ASCIIDelimiterTransport interface {
+	ASCIIInputDelimiter() byte
+}
+
+// asciiDelimiters 返回 ASCII 设备使用的结束符，没有设备时为 DefaultASCIIDelimiter
+func asciiDelimiters(devices []*ModbusDevice) []byte {
+	delimiters := make([]byte, 0, 1)
+	for _, device := range devices {
+		if device.FrameType != FrameTypeASCII {
+			continue
+		}
+		delimiter := DefaultASCIIDelimiter
+		if t, ok := device.Transport.(ASCIIDelimiterTransport); ok {
+			delimiter = t.ASCIIInputDelimiter()
+		}
+		if bytes.IndexByte(delimiters, delimiter) < 0 {
+			delimiters = append(delimiters, delimiter)
+		}
+	}
+	if len(delimiters) == 0 {
+		delimiters = append(delimiters, DefaultASCIIDelimiter)
+	}
+	return delimiters
+}
+
+// indexASCIIDelimiter 返回 buf 中第一个结束符的位置，没有时返回 -1
+func indexASCIIDelimiter(buf []byte, delimiters []byte) int {
+	for i, b := range buf {
+		if bytes.IndexByte(delimiters, b) >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// ASCIIFrame Modbus ASCII 帧，以 ':' 开始，地址、PDU 和 LRC 编码为大写十六进制字符，以 CRLF 结束，
+// 解析时 CR 之后的结束符可以是修改后的任意字符
+type ASCIIFrame struct {
+	SlaveId byte
+	PDU     *ProtocolDataUnit
+	LRC     byte
+}
+
+func (f *ASCIIFrame) ToBytes() []byte {
+	raw := make([]byte, 0, len(f.PDU.Data)+3)
+	raw = append(raw, f.SlaveId, f.PDU.FunctionCode)
+	raw = append(raw, f.PDU.Data...)
+	lrc := LRC{}
+	raw = append(raw, lrc.Reset().PushBytes(raw).Value())
+
+	data := make([]byte, 1+len(raw)*2+len(asciiEnd))
+	data[0] = asciiStart
+	hex.Encode(data[1:], raw)
+	copy(data[1+len(raw)*2:], asciiEnd)
+	return bytes.ToUpper(data)
+}
+
+func NewASCIIFrame(slaveId byte, pdu *ProtocolDataUnit) (frame *ASCIIFrame, err error) {
+	length := 1 + (len(pdu.Data)+3)*2 + len(asciiEnd)
+	if length > asciiMaxSize {
+		err = fmt.Errorf("modbus: length of data '%v' must not be bigger than '%v'", length, asciiMaxSize)
+		return
+	}
+	frame = &ASCIIFrame{
+		SlaveId: slaveId,
+		PDU:     pdu,
+	}
+	return
+}
+
+func NewASCIIFrameFromBytes(messageData []byte) (frame *ASCIIFrame, err error) {
+	length := len(messageData)
+	if length < asciiMinSize {
+		err = fmt.Errorf("modbus: message length '%v' does not meet minimum '%v'", length, asciiMinSize)
+		return
+	}
+	if messageData[0] != asciiStart {
+		err = fmt.Errorf("modbus: message start '%v' does not match '%v'", messageData[0], asciiStart)
+		return
+	}
+	if messageData[length-2] != asciiEnd[0] {
+		err = fmt.Errorf("modbus: message end '%q' does not match '%q'", messageData[length-2:], asciiEnd)
+		return
+	}
+	if (length-3)%2 != 0 {
+		err = fmt.Errorf("modbus: message length '%v' is not an even number of hex characters", length-3)
+		return
+	}
+	raw := make([]byte, (length-3)/2)
+	if _, err = hex.Decode(raw, messageData[1:length-2]); err != nil {
+		err = fmt.Errorf("modbus: message is not valid hex: %w", err)
+		return
+	}
+	lrc := LRC{}
+	lrc.Reset().PushBytes(raw[:len(raw)-1])
+	if lrc.Value() != raw[len(raw)-1] {
+		err = fmt.Errorf("modbus: response lrc '%v' does not match expected '%v'", raw[len(raw)-1], lrc.Value())
+		return
+	}
+	frame = &ASCIIFrame{
+		SlaveId: raw[0],
+		PDU: &ProtocolDataUnit{
+			FunctionCode: raw[1],
+			Data:         raw[2 : len(raw)-1],
+		},
+		LRC: raw[len(raw)-1],
+	}
+	return
+}
+
+// asciiSlaveId 返回 ASCII 帧中的从站地址，不校验 LRC
+func asciiSlaveId(messageData []byte) (byte, bool) {
+	if len(messageData) < 3 || messageData[0] != asciiStart {
+		return 0, false
+	}
+	var id [1]byte
+	if _, err := hex.Decode(id[:], messageData[1:3]); err != nil {
+		return 0, false
+	}
+	return id[0], true
+}
+
+// ReadASCIIFrame 从 r 逐字节读取一帧 ASCII 数据，不会读取帧之后的数据。':' 之前的数据被丢弃，收到新的 ':' 时重新开始，读取到 LF 结束。
+// r 支持 SetReadDeadline 且 charTimeout 大于 0 时，收到 ':' 后两个字符的间隔超过 charTimeout 返回错误
+func ReadASCIIFrame(r io.Reader, charTimeout time.Duration) ([]byte, error) {
+	return readASCIIFrame(r, charTimeout, []byte{DefaultASCIIDelimiter})
+}
+
+// readASCIIFrame 同 ReadASCIIFrame，读取到 delimiters 中的任一结束符时结束
+func readASCIIFrame(r io.Reader, charTimeout time.Duration, delimiters []byte) ([]byte, error) {
+	deadlineReader, _ := r.(interface{ SetReadDeadline(time.Time) error })
+	frame := make([]byte, 0, asciiMaxSize)
+	var b [1]byte
+	for {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			if len(frame) > 0 && errors.Is(err, os.ErrDeadlineExceeded) {
+				return nil, fmt.Errorf("modbus: inter-character timeout after '%v' bytes: %w", len(frame), err)
+			}
+			return nil, err
+		}
+		switch {
+		case b[0] == asciiStart:
+			frame = append(frame[:0], b[0])
+		case len(frame) == 0:
+			continue
+		case len(frame) >= asciiMaxSize:
+			return nil, fmt.Errorf("modbus: message length must not be bigger than '%v'", asciiMaxSize)
+		default:
+			frame = append(frame, b[0])
+			if bytes.IndexByte(delimiters, b[0]) >= 0 {
+				return frame, nil
+			}
+		}
+		if deadlineReader != nil && charTimeout > 0 {
+			if err := deadlineReader.SetReadDeadline(time.Now().Add(charTimeout)); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package common
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLRC(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want byte
+	}{
+		{name: "empty", data: nil, want: 0x00},
+		{name: "specification example", data: []byte{0xF7, 0x03, 0x13, 0x89, 0x00, 0x0A}, want: 0x60},
+		{name: "single byte", data: []byte{0x01}, want: 0xFF},
+		{name: "sum wraps", data: []byte{0xFF, 0xFF, 0x02}, want: 0x00},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lrc := LRC{}
+			if got := lrc.Reset().PushBytes(tt.data).Value(); got != tt.want {
+				t.Fatalf("lrc = %#x, want %#x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestASCIIFrameToBytes(t *testing.T) {
+	frame, err := NewASCIIFrame(0xF7, &ProtocolDataUnit{FunctionCode: 0x03, Data: []byte{0x13, 0x89, 0x00, 0x0A}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(frame.ToBytes()), ":F7031389000A60\r\n"; got != want {
+		t.Fatalf("frame = %q, want %q", got, want)
+	}
+}
+
+func TestNewASCIIFrameFromBytes(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "valid", data: ":F7031389000A60\r\n"},
+		{name: "changed delimiter", data: ":F7031389000A60\r#"},
+		{name: "lower case hex", data: ":f7031389000a60\r\n"},
+		{name: "too short", data: ":0103\r\n", wantErr: "minimum"},
+		{name: "missing start", data: "F7031389000A60\r\n\n", wantErr: "start"},
+		{name: "missing carriage return", data: ":F7031389000A60\n\n", wantErr: "end"},
+		{name: "odd hex characters", data: ":F7031389000A600\r\n", wantErr: "even"},
+		{name: "invalid hex", data: ":F7031389000G60\r\n", wantErr: "hex"},
+		{name: "lrc mismatch", data: ":F7031389000A61\r\n", wantErr: "lrc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, err := NewASCIIFrameFromBytes([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if frame.SlaveId != 0xF7 || frame.PDU.FunctionCode != 0x03 || !bytes.Equal(frame.PDU.Data, []byte{0x13, 0x89, 0x00, 0x0A}) {
+				t.Fatalf("frame = %v %v %v", frame.SlaveId, frame.PDU.FunctionCode, frame.PDU.Data)
+			}
+		})
+	}
+}
+
+func TestReadASCIIFrame(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		delimiters []byte
+		want       string
+		wantErr    bool
+	}{
+		{name: "single frame", input: ":010300000001FB\r\n", want: ":010300000001FB\r\n"},
+		{name: "noise before start", input: "\x00xx:010300000001FB\r\n", want: ":010300000001FB\r\n"},
+		{name: "resync on new start", input: ":0103:010300000001FB\r\n", want: ":010300000001FB\r\n"},
+		{name: "stops after frame", input: ":010300000001FB\r\n:0104", want: ":010300000001FB\r\n"},
+		{name: "changed delimiter", input: ":010300000001FB\r#", delimiters: []byte{'#'}, want: ":010300000001FB\r#"},
+		{name: "default delimiter is not an end when changed", input: ":010300000001FB\r\n", delimiters: []byte{'#'}, wantErr: true},
+		{name: "any of several delimiters", input: ":010300000001FB\r#", delimiters: []byte{'\n', '#'}, want: ":010300000001FB\r#"},
+		{name: "truncated", input: ":0103", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delimiters := tt.delimiters
+			if delimiters == nil {
+				delimiters = []byte{DefaultASCIIDelimiter}
+			}
+			frame, err := readASCIIFrame(strings.NewReader(tt.input), 0, delimiters)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %q, want error", frame)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(frame) != tt.want {
+				t.Fatalf("frame = %q, want %q", frame, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadASCIIFrameLeavesFollowingData(t *testing.T) {
+	r := strings.NewReader(":010300000001FB\r\n:010400000001FA\r\n")
+	for _, want := range []string{":010300000001FB\r\n", ":010400000001FA\r\n"} {
+		frame, err := ReadASCIIFrame(r, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(frame) != want {
+			t.Fatalf("frame = %q, want %q", frame, want)
+		}
+	}
+}
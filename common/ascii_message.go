@@ -0,0 +1,43 @@
+package common
+
+import "fmt"
+
+// ASCIIMessage Modbus ASCII 消息定义，实现 Message 接口
+type ASCIIMessage struct {
+}
+
+// Encode 编码数据帧为 Modbus ASCII 消息格式
+func (m ASCIIMessage) Encode(slaveId byte, pdu *ProtocolDataUnit) (messageData []byte, err error) {
+	frame, err := NewASCIIFrame(slaveId, pdu)
+	if err != nil {
+		return
+	}
+	messageData = frame.ToBytes()
+	return
+}
+
+func (m ASCIIMessage) Decode(messageData []byte) (pdu *ProtocolDataUnit, err error) {
+	frame, err := NewASCIIFrameFromBytes(messageData)
+	if err != nil {
+		return
+	}
+	pdu = frame.PDU
+	return
+}
+
+func (m ASCIIMessage) Verify(requestData []byte, responseData []byte) (err error) {
+	length := len(responseData)
+	// Minimum size (including start, address, function, LRC and CRLF)
+	if length < asciiMinSize {
+		err = fmt.Errorf("modbus: response length '%v' does not meet minimum '%v'", length, asciiMinSize)
+		return
+	}
+	// Slave address must match
+	responseId, _ := asciiSlaveId(responseData)
+	requestId, _ := asciiSlaveId(requestData)
+	if responseId != requestId {
+		err = fmt.Errorf("modbus: response slave id '%v' does not match request '%v'", responseId, requestId)
+		return
+	}
+	return
+}
@@ -1,5 +1,10 @@
 package common
 
+import (
+	"fmt"
+	"math/bits"
+)
+
 // BitVector 是一个位向量结构，用于高效存储和操作大量布尔值
 type BitVector struct {
 	bits []uint64
@@ -24,9 +29,14 @@ func NewBitVector(size uint) *BitVector {
 
 func NewBitVectorFromBooleans(values []bool) *BitVector {
 	bv := NewBitVector(uint(len(values)))
-	for i, value := range values {
-		bv.Set(uint(i), value)
-	}
+	bv.SetRange(0, values)
+	return bv
+}
+
+// NewBitVectorFromBytes 由按 Modbus 线圈顺序打包的字节（每字节 bit 0 在前）创建 size 位的位向量
+func NewBitVectorFromBytes(data []byte, size uint) *BitVector {
+	bv := NewBitVector(size)
+	bv.Load(data)
 	return bv
 }
 
@@ -61,20 +71,16 @@ func (bv *BitVector) Size() uint {
 }
 
 func (bv *BitVector) Load(dataBuffer []byte) {
-	bitIndex := uint(0)
-	for _, b := range dataBuffer {
-		if bitIndex >= bv.size {
+	for i, b := range dataBuffer {
+		index := uint(i) * 8
+		if index >= bv.size {
 			break
 		}
-		for i := uint(0); i < 8; i++ {
-			if bitIndex >= bv.size {
-				break
-			}
-			bit := (b >> i) & 1
-			bv.Set(bitIndex, bit == 1)
-			bitIndex++
-		}
+		word := index / 64
+		shift := index % 64
+		bv.bits[word] = bv.bits[word]&^(0xFF<<shift) | uint64(b)<<shift
 	}
+	bv.clearTail()
 }
 
 func (bv *BitVector) ToString() string {
@@ -90,16 +96,180 @@ func (bv *BitVector) ToString() string {
 }
 
 func (bv *BitVector) ToBytes() []byte {
-	numBytes := (bv.size + 7) / 8
-	data := make([]byte, numBytes)
+	data := make([]byte, (bv.size+7)/8)
+	for i := range data {
+		data[i] = byte(bv.bits[i/8] >> (uint(i) % 8 * 8))
+	}
+	return data
+}
 
-	for i := uint(0); i < bv.size; i++ {
+// TrySet 设置指定索引位置的状态，索引越界时返回错误
+func (bv *BitVector) TrySet(index uint, state bool) error {
+	if err := bv.checkRange(index, 1); err != nil {
+		return err
+	}
+	bv.Set(index, state)
+	return nil
+}
+
+// TryGet 获取指定索引位置的状态，索引越界时返回错误
+func (bv *BitVector) TryGet(index uint) (bool, error) {
+	if err := bv.checkRange(index, 1); err != nil {
+		return false, err
+	}
+	return bv.Get(index), nil
+}
+
+// SetRange 从 start 开始依次设置 values 中的状态，越界时 panic
+func (bv *BitVector) SetRange(start uint, values []bool) {
+	if err := bv.checkRange(start, uint(len(values))); err != nil {
+		panic(err.Error())
+	}
+	for i, value := range values {
+		bv.Set(start+uint(i), value)
+	}
+}
+
+// TrySetRange 从 start 开始依次设置 values 中的状态，越界时返回错误且不做修改
+func (bv *BitVector) TrySetRange(start uint, values []bool) error {
+	if err := bv.checkRange(start, uint(len(values))); err != nil {
+		return err
+	}
+	bv.SetRange(start, values)
+	return nil
+}
+
+// GetRange 返回从 start 开始 count 个位的状态，越界时 panic
+func (bv *BitVector) GetRange(start uint, count uint) []bool {
+	if err := bv.checkRange(start, count); err != nil {
+		panic(err.Error())
+	}
+	values := make([]bool, count)
+	for i := range values {
+		values[i] = bv.Get(start + uint(i))
+	}
+	return values
+}
+
+// TryGetRange 返回从 start 开始 count 个位的状态，越界时返回错误
+func (bv *BitVector) TryGetRange(start uint, count uint) ([]bool, error) {
+	if err := bv.checkRange(start, count); err != nil {
+		return nil, err
+	}
+	return bv.GetRange(start, count), nil
+}
+
+// Fill 将从 start 开始 count 个位设置为 state，越界时 panic
+func (bv *BitVector) Fill(start uint, count uint, state bool) {
+	if err := bv.checkRange(start, count); err != nil {
+		panic(err.Error())
+	}
+	for i := start; i < start+count; i++ {
+		bv.Set(i, state)
+	}
+}
+
+// Slice 返回 [start, end) 区间的副本，越界时 panic
+func (bv *BitVector) Slice(start uint, end uint) *BitVector {
+	sub, err := bv.TrySlice(start, end)
+	if err != nil {
+		panic(err.Error())
+	}
+	return sub
+}
+
+// TrySlice 返回 [start, end) 区间的副本，越界时返回错误
+func (bv *BitVector) TrySlice(start uint, end uint) (*BitVector, error) {
+	if end < start {
+		return nil, fmt.Errorf("modbus: bit range end '%v' is less than start '%v'", end, start)
+	}
+	if err := bv.checkRange(start, end-start); err != nil {
+		return nil, err
+	}
+	sub := NewBitVector(end - start)
+	for i := start; i < end; i++ {
 		if bv.Get(i) {
-			byteIndex := i / 8
-			bitInByte := i % 8
-			data[byteIndex] |= 1 << bitInByte
+			sub.Set(i-start, true)
 		}
 	}
+	return sub, nil
+}
 
-	return data
+// Clone 返回位向量的副本
+func (bv *BitVector) Clone() *BitVector {
+	return &BitVector{
+		bits: append([]uint64(nil), bv.bits...),
+		size: bv.size,
+	}
+}
+
+// ToBooleans 将位向量导出为 []bool
+func (bv *BitVector) ToBooleans() []bool {
+	return bv.GetRange(0, bv.size)
+}
+
+// Count 返回状态为 true 的位数
+func (bv *BitVector) Count() uint {
+	count := 0
+	for _, word := range bv.bits {
+		count += bits.OnesCount64(word)
+	}
+	return uint(count)
+}
+
+// ForEachSet 按索引从小到大对每个状态为 true 的位调用 fn
+func (bv *BitVector) ForEachSet(fn func(index uint)) {
+	for i, word := range bv.bits {
+		for word != 0 {
+			bit := uint(bits.TrailingZeros64(word))
+			fn(uint(i)*64 + bit)
+			word &= word - 1
+		}
+	}
+}
+
+// Equal 判断两个位向量的大小和各位状态是否相同，other 为 nil 时返回 false
+func (bv *BitVector) Equal(other *BitVector) bool {
+	if other == nil || bv.size != other.size {
+		return false
+	}
+	for i, word := range bv.bits {
+		if word != other.bits[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff 返回两个位向量状态不同的索引，按从小到大排列，大小不同时较短一方超出部分按 false 比较
+func (bv *BitVector) Diff(other *BitVector) []uint {
+	var indexes []uint
+	for i := 0; i < max(len(bv.bits), len(other.bits)); i++ {
+		var a, b uint64
+		if i < len(bv.bits) {
+			a = bv.bits[i]
+		}
+		if i < len(other.bits) {
+			b = other.bits[i]
+		}
+		for word := a ^ b; word != 0; word &= word - 1 {
+			indexes = append(indexes, uint(i)*64+uint(bits.TrailingZeros64(word)))
+		}
+	}
+	return indexes
+}
+
+// checkRange 检查 [start, start+count) 在位向量范围内
+func (bv *BitVector) checkRange(start uint, count uint) error {
+	if start > bv.size || count > bv.size-start {
+		return fmt.Errorf("modbus: bit range '%v' + '%v' exceeds size '%v'", start, count, bv.size)
+	}
+	return nil
+}
+
+// clearTail 清除超出 size 的位，保证 Equal、Count 等按字比较的结果正确
+func (bv *BitVector) clearTail() {
+	if tail := bv.size % 64; tail != 0 {
+		bv.bits[len(bv.bits)-1] &= 1<<tail - 1
+	}
 }
@@ -0,0 +1,147 @@
+package common
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestBitVectorLoadClearsTail 大小不是 8 的整数倍时，Load 清除超出大小的位，Equal 和 Count 不受影响
+func TestBitVectorLoadClearsTail(t *testing.T) {
+	tests := []struct {
+		name  string
+		size  uint
+		data  []byte
+		count uint
+		bytes []byte
+	}{
+		{name: "10 bits", size: 10, data: []byte{0xFF, 0xFF}, count: 10, bytes: []byte{0xFF, 0x03}},
+		{name: "3 bits", size: 3, data: []byte{0xFD}, count: 2, bytes: []byte{0x05}},
+		{name: "70 bits", size: 70, data: []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, count: 70, bytes: []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x3F}},
+		{name: "extra bytes ignored", size: 4, data: []byte{0x1F, 0xFF}, count: 4, bytes: []byte{0x0F}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bv := NewBitVectorFromBytes(tt.data, tt.size)
+			if bv.Count() != tt.count {
+				t.Fatalf("count = %v, want %v", bv.Count(), tt.count)
+			}
+			if !slices.Equal(bv.ToBytes(), tt.bytes) {
+				t.Fatalf("bytes = % X, want % X", bv.ToBytes(), tt.bytes)
+			}
+			same := NewBitVectorFromBytes(tt.bytes, tt.size)
+			if !bv.Equal(same) || !same.Equal(bv) || len(bv.Diff(same)) != 0 {
+				t.Fatalf("%v not equal to %v", bv.ToString(), same.ToString())
+			}
+		})
+	}
+
+	// 重新加载时覆盖之前的位
+	bv := NewBitVectorFromBytes([]byte{0xFF, 0xFF}, 12)
+	bv.Load([]byte{0x01})
+	if bv.ToString() != "100000001111" {
+		t.Fatalf("reloaded = %v", bv.ToString())
+	}
+}
+
+func TestBitVectorDiff(t *testing.T) {
+	a := NewBitVectorFromBooleans([]bool{true, false, true, false})
+	b := NewBitVector(100)
+	b.Set(0, true)
+	b.Set(3, true)
+	b.Set(64, true)
+	b.Set(99, true)
+	want := []uint{2, 3, 64, 99}
+	if diff := a.Diff(b); !slices.Equal(diff, want) {
+		t.Fatalf("diff = %v, want %v", diff, want)
+	}
+	if diff := b.Diff(a); !slices.Equal(diff, want) {
+		t.Fatalf("reverse diff = %v, want %v", diff, want)
+	}
+	if a.Equal(b) {
+		t.Fatal("vectors of different sizes are equal")
+	}
+	// 大小不同但多出的位为 false 时没有差异，但不相等
+	c := NewBitVectorFromBooleans([]bool{true, false, true, false, false})
+	if diff := a.Diff(c); len(diff) != 0 || a.Equal(c) {
+		t.Fatalf("diff = %v, equal = %v", diff, a.Equal(c))
+	}
+	if a.Equal(nil) {
+		t.Fatal("equal to nil")
+	}
+}
+
+func TestBitVectorSlice(t *testing.T) {
+	bv := NewBitVector(200)
+	for _, i := range []uint{0, 60, 63, 64, 65, 127, 128, 199} {
+		bv.Set(i, true)
+	}
+	tests := []struct {
+		start, end uint
+		want       []uint
+	}{
+		{start: 60, end: 70, want: []uint{0, 3, 4, 5}},
+		{start: 63, end: 129, want: []uint{0, 1, 2, 64, 65}},
+		{start: 64, end: 64, want: nil},
+		{start: 0, end: 200, want: []uint{0, 60, 63, 64, 65, 127, 128, 199}},
+	}
+	for _, tt := range tests {
+		sub := bv.Slice(tt.start, tt.end)
+		if sub.Size() != tt.end-tt.start {
+			t.Fatalf("slice [%v, %v) size = %v", tt.start, tt.end, sub.Size())
+		}
+		var set []uint
+		sub.ForEachSet(func(index uint) { set = append(set, index) })
+		if !slices.Equal(set, tt.want) {
+			t.Fatalf("slice [%v, %v) set bits = %v, want %v", tt.start, tt.end, set, tt.want)
+		}
+		if sub.Count() != uint(len(tt.want)) {
+			t.Fatalf("slice [%v, %v) count = %v", tt.start, tt.end, sub.Count())
+		}
+	}
+}
+
+// TestBitVectorTry Try 方法越界时返回错误而不是 panic，且不修改位向量
+func TestBitVectorTry(t *testing.T) {
+	bv := NewBitVector(10)
+	if err := bv.TrySet(10, true); err == nil {
+		t.Error("TrySet(10): got nil error")
+	}
+	if _, err := bv.TryGet(10); err == nil {
+		t.Error("TryGet(10): got nil error")
+	}
+	if err := bv.TrySetRange(8, []bool{true, true, true}); err == nil {
+		t.Error("TrySetRange(8, 3 values): got nil error")
+	}
+	if _, err := bv.TryGetRange(5, 6); err == nil {
+		t.Error("TryGetRange(5, 6): got nil error")
+	}
+	if _, err := bv.TryGetRange(^uint(0), 2); err == nil {
+		t.Error("TryGetRange with overflowing start: got nil error")
+	}
+	if _, err := bv.TrySlice(5, 4); err == nil {
+		t.Error("TrySlice(5, 4): got nil error")
+	}
+	if _, err := bv.TrySlice(0, 11); err == nil {
+		t.Error("TrySlice(0, 11): got nil error")
+	}
+	if bv.Count() != 0 {
+		t.Fatalf("count after failed calls = %v, want 0", bv.Count())
+	}
+
+	if err := bv.TrySetRange(8, []bool{true, true}); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := bv.TryGet(9); err != nil || !state {
+		t.Fatalf("TryGet(9) = %v, %v", state, err)
+	}
+	if sub, err := bv.TrySlice(8, 10); err != nil || sub.ToString() != "11" {
+		t.Fatalf("TrySlice(8, 10) = %v, %v", sub, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Set(10): did not panic")
+		}
+	}()
+	bv.Set(10, true)
+}
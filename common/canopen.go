@@ -0,0 +1,5 @@
+package common
+
+// MEITypeCANopenGeneralReference CANopen General Reference (功能码 0x2B) 的 MEI 类型，
+// 用于封装 CANopen 对象字典的 SDO 读写，数据格式由 CANopen 规范 (CiA 309-2) 定义
+const MEITypeCANopenGeneralReference = 0x0D
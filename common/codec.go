@@ -0,0 +1,153 @@
+package common
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// ByteOrder 多寄存器数值的字节序，以 32 位数值 0xAABBCCDD 的 4 个字节 A B C D 在寄存器中的排列命名
+type ByteOrder int
+
+const (
+	ByteOrderABCD ByteOrder = iota // 高字在前，字内高字节在前（大端）
+	ByteOrderCDAB                  // 低字在前，字内高字节在前
+	ByteOrderBADC                  // 高字在前，字内低字节在前
+	ByteOrderDCBA                  // 低字在前，字内低字节在前（小端）
+)
+
+func (o ByteOrder) String() string {
+	switch o {
+	case ByteOrderABCD:
+		return "ABCD"
+	case ByteOrderCDAB:
+		return "CDAB"
+	case ByteOrderBADC:
+		return "BADC"
+	case ByteOrderDCBA:
+		return "DCBA"
+	}
+	return fmt.Sprintf("ByteOrder(%d)", int(o))
+}
+
+// wordSwapped 是否低字在前
+func (o ByteOrder) wordSwapped() bool {
+	return o == ByteOrderCDAB || o == ByteOrderDCBA
+}
+
+// byteSwapped 是否字内低字节在前
+func (o ByteOrder) byteSwapped() bool {
+	return o == ByteOrderBADC || o == ByteOrderDCBA
+}
+
+// Number 可由寄存器编解码的数值类型
+type Number interface {
+	int16 | uint16 | int32 | uint32 | float32 | int64 | uint64 | float64
+}
+
+// RegisterCount 返回数值类型占用的寄存器数量
+func RegisterCount[T Number]() int {
+	var value T
+	return binary.Size(value) / 2
+}
+
+// EncodeWords 将数值按字节序编码为寄存器值
+func EncodeWords[T Number](values []T, order ByteOrder) []uint16 {
+	n := RegisterCount[T]()
+	words := make([]uint16, 0, len(values)*n)
+	for _, value := range values {
+		words = order.appendWords(words, toBits(value), n)
+	}
+	return words
+}
+
+// DecodeWords 将寄存器值按字节序解码为数值，寄存器数量必须是数值长度的整数倍
+func DecodeWords[T Number](words []uint16, order ByteOrder) ([]T, error) {
+	n := RegisterCount[T]()
+	if len(words)%n != 0 {
+		return nil, fmt.Errorf("modbus: register count '%v' is not a multiple of '%v'", len(words), n)
+	}
+	values := make([]T, len(words)/n)
+	for i := range values {
+		values[i] = fromBits[T](order.bits(words[i*n : i*n+n]))
+	}
+	return values, nil
+}
+
+// EncodeRegisters 将数值按字节序编码为寄存器
+func EncodeRegisters[T Number](values []T, order ByteOrder) []*Register {
+	return NewRegistersFromUInt16s(EncodeWords(values, order))
+}
+
+// DecodeRegisters 将寄存器按字节序解码为数值，寄存器数量必须是数值长度的整数倍
+func DecodeRegisters[T Number](registers []*Register, order ByteOrder) ([]T, error) {
+	return DecodeWords[T](RegisterValues(registers), order)
+}
+
+// appendWords 将 n 个寄存器长度的数值 bits 按字节序追加到 words
+func (o ByteOrder) appendWords(words []uint16, bits uint64, n int) []uint16 {
+	for i := 0; i < n; i++ {
+		// 大端顺序下第 i 个字
+		word := uint16(bits >> (16 * (n - 1 - i)))
+		if o.wordSwapped() {
+			word = uint16(bits >> (16 * i))
+		}
+		if o.byteSwapped() {
+			word = word<<8 | word>>8
+		}
+		words = append(words, word)
+	}
+	return words
+}
+
+// bits 将按字节序排列的寄存器值还原为数值
+func (o ByteOrder) bits(words []uint16) uint64 {
+	var bits uint64
+	n := len(words)
+	for i, word := range words {
+		if o.byteSwapped() {
+			word = word<<8 | word>>8
+		}
+		shift := 16 * (n - 1 - i)
+		if o.wordSwapped() {
+			shift = 16 * i
+		}
+		bits |= uint64(word) << shift
+	}
+	return bits
+}
+
+// toBits 返回数值的二进制表示
+func toBits[T Number](value T) uint64 {
+	switch v := any(value).(type) {
+	case float32:
+		return uint64(math.Float32bits(v))
+	case float64:
+		return math.Float64bits(v)
+	}
+	return uint64(value)
+}
+
+// fromBits 由二进制表示还原数值
+func fromBits[T Number](bits uint64) T {
+	var value T
+	switch any(value).(type) {
+	case int16:
+		return T(int16(bits))
+	case uint16:
+		return T(uint16(bits))
+	case int32:
+		return T(int32(bits))
+	case uint32:
+		return T(uint32(bits))
+	case float32:
+		return T(math.Float32frombits(uint32(bits)))
+	case int64:
+		return T(int64(bits))
+	case uint64:
+		return T(bits)
+	case float64:
+		return T(math.Float64frombits(bits))
+	}
+	return value
+}
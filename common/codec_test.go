@@ -0,0 +1,121 @@
+package common
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// codecCase 数值按各字节序编码后的寄存器值
+type codecCase[T Number] struct {
+	value T
+	words map[ByteOrder][]uint16
+}
+
+func checkCodec[T Number](t *testing.T, tests []codecCase[T]) {
+	t.Helper()
+	for _, tt := range tests {
+		for order, want := range tt.words {
+			if words := EncodeWords([]T{tt.value}, order); !slices.Equal(words, want) {
+				t.Errorf("%v %v: encode = %04X, want %04X", tt.value, order, words, want)
+			}
+			values, err := DecodeWords[T](want, order)
+			if err != nil || len(values) != 1 || values[0] != tt.value {
+				t.Errorf("%v %v: decode = %v, %v", tt.value, order, values, err)
+			}
+			values, err = DecodeRegisters[T](EncodeRegisters([]T{tt.value}, order), order)
+			if err != nil || len(values) != 1 || values[0] != tt.value {
+				t.Errorf("%v %v: register round trip = %v, %v", tt.value, order, values, err)
+			}
+		}
+	}
+}
+
+func TestCodecByteOrder(t *testing.T) {
+	t.Run("float32", func(t *testing.T) {
+		checkCodec(t, []codecCase[float32]{
+			{value: 1.0, words: map[ByteOrder][]uint16{
+				ByteOrderABCD: {0x3F80, 0x0000},
+				ByteOrderCDAB: {0x0000, 0x3F80},
+				ByteOrderBADC: {0x803F, 0x0000},
+				ByteOrderDCBA: {0x0000, 0x803F},
+			}},
+			{value: -2.5, words: map[ByteOrder][]uint16{
+				ByteOrderABCD: {0xC020, 0x0000},
+				ByteOrderDCBA: {0x0000, 0x20C0},
+			}},
+		})
+	})
+	t.Run("uint32", func(t *testing.T) {
+		checkCodec(t, []codecCase[uint32]{
+			{value: 0xAABBCCDD, words: map[ByteOrder][]uint16{
+				ByteOrderABCD: {0xAABB, 0xCCDD},
+				ByteOrderCDAB: {0xCCDD, 0xAABB},
+				ByteOrderBADC: {0xBBAA, 0xDDCC},
+				ByteOrderDCBA: {0xDDCC, 0xBBAA},
+			}},
+		})
+	})
+	t.Run("int16", func(t *testing.T) {
+		checkCodec(t, []codecCase[int16]{
+			{value: -2, words: map[ByteOrder][]uint16{
+				ByteOrderABCD: {0xFFFE},
+				ByteOrderCDAB: {0xFFFE},
+				ByteOrderBADC: {0xFEFF},
+				ByteOrderDCBA: {0xFEFF},
+			}},
+		})
+	})
+	t.Run("uint64", func(t *testing.T) {
+		checkCodec(t, []codecCase[uint64]{
+			{value: 0x0102030405060708, words: map[ByteOrder][]uint16{
+				ByteOrderABCD: {0x0102, 0x0304, 0x0506, 0x0708},
+				ByteOrderCDAB: {0x0708, 0x0506, 0x0304, 0x0102},
+				ByteOrderBADC: {0x0201, 0x0403, 0x0605, 0x0807},
+				ByteOrderDCBA: {0x0807, 0x0605, 0x0403, 0x0201},
+			}},
+		})
+	})
+	t.Run("float64", func(t *testing.T) {
+		checkCodec(t, []codecCase[float64]{
+			{value: 1.0, words: map[ByteOrder][]uint16{
+				ByteOrderABCD: {0x3FF0, 0x0000, 0x0000, 0x0000},
+				ByteOrderDCBA: {0x0000, 0x0000, 0x0000, 0xF03F},
+			}},
+		})
+	})
+}
+
+func checkRoundTrip[T Number](t *testing.T, values []T) {
+	t.Helper()
+	for _, order := range []ByteOrder{ByteOrderABCD, ByteOrderCDAB, ByteOrderBADC, ByteOrderDCBA} {
+		words := EncodeWords(values, order)
+		if len(words) != len(values)*RegisterCount[T]() {
+			t.Fatalf("%v: %v words for %v values", order, len(words), len(values))
+		}
+		decoded, err := DecodeWords[T](words, order)
+		if err != nil || !slices.Equal(decoded, values) {
+			t.Fatalf("%v: round trip = %v, %v, want %v", order, decoded, err, values)
+		}
+	}
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	checkRoundTrip(t, []int16{0, 1, -1, math.MinInt16, math.MaxInt16})
+	checkRoundTrip(t, []int32{0, -1, math.MinInt32, math.MaxInt32, -123456})
+	checkRoundTrip(t, []int64{0, -1, math.MinInt64, math.MaxInt64, -1234567890123})
+	checkRoundTrip(t, []uint64{0, 1, math.MaxUint64, 0x8000000000000000})
+	checkRoundTrip(t, []float64{0, -1.5, math.MaxFloat64, math.SmallestNonzeroFloat64, math.Inf(-1)})
+}
+
+func TestDecodeWordsLength(t *testing.T) {
+	if _, err := DecodeWords[float32]([]uint16{1, 2, 3}, ByteOrderABCD); err == nil {
+		t.Fatal("3 words as float32: got nil error")
+	}
+	if _, err := DecodeRegisters[int64](NewRegistersFromUInt16s([]uint16{1, 2}), ByteOrderABCD); err == nil {
+		t.Fatal("2 registers as int64: got nil error")
+	}
+	if values, err := DecodeWords[uint16](nil, ByteOrderABCD); err != nil || len(values) != 0 {
+		t.Fatalf("no words = %v, %v", values, err)
+	}
+}
@@ -0,0 +1,129 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MEITypeReadDeviceIdentification 读取设备标识 (功能码 0x2B) 的 MEI 类型
+const MEITypeReadDeviceIdentification = 0x0E
+
+// 读取设备标识的读取码
+const (
+	ReadDeviceIDCodeBasic    byte = 0x01 // 流式读取基本对象
+	ReadDeviceIDCodeRegular  byte = 0x02 // 流式读取常规对象
+	ReadDeviceIDCodeExtended byte = 0x03 // 流式读取扩展对象
+	ReadDeviceIDCodeSpecific byte = 0x04 // 读取单个对象
+)
+
+// 设备标识的对象 ID
+const (
+	DeviceObjectVendorName          byte = 0x00
+	DeviceObjectProductCode         byte = 0x01
+	DeviceObjectProductVersion      byte = 0x02
+	DeviceObjectVendorUrl           byte = 0x03
+	DeviceObjectProductName         byte = 0x04
+	DeviceObjectModelName           byte = 0x05
+	DeviceObjectUserApplicationName byte = 0x06
+	DeviceObjectPrivateStart        byte = 0x80
+)
+
+// 设备标识的一致性等级，0x80 位表示支持读取单个对象
+const (
+	ConformityLevelBasic            byte = 0x01
+	ConformityLevelRegular          byte = 0x02
+	ConformityLevelExtended         byte = 0x03
+	ConformityLevelIndividualAccess byte = 0x80
+)
+
+// DeviceObjectRange 返回读取码对应的对象 ID 范围 [first, last]
+func DeviceObjectRange(readCode byte) (first, last byte, err error) {
+	switch readCode {
+	case ReadDeviceIDCodeBasic:
+		return DeviceObjectVendorName, DeviceObjectProductVersion, nil
+	case ReadDeviceIDCodeRegular:
+		return DeviceObjectVendorName, DeviceObjectPrivateStart - 1, nil
+	case ReadDeviceIDCodeExtended, ReadDeviceIDCodeSpecific:
+		return DeviceObjectVendorName, 0xFF, nil
+	}
+	return 0, 0, fmt.Errorf("modbus: read device id code '%v' is out of range [1, 4]", readCode)
+}
+
+// standardObject 返回标准对象对应的字段，对象 ID 不是标准对象时返回 nil
+func (d *DeviceIdentification) standardObject(objectId byte) *string {
+	switch objectId {
+	case DeviceObjectVendorName:
+		return &d.VendorName
+	case DeviceObjectProductCode:
+		return &d.ProductCode
+	case DeviceObjectProductVersion:
+		return &d.ProductVersion
+	case DeviceObjectVendorUrl:
+		return &d.VendorUrl
+	case DeviceObjectProductName:
+		return &d.ProductName
+	case DeviceObjectModelName:
+		return &d.ModelName
+	case DeviceObjectUserApplicationName:
+		return &d.UserApplicationName
+	}
+	return nil
+}
+
+// Object 返回对象 ID 对应的值，未配置的对象返回 false
+func (d *DeviceIdentification) Object(objectId byte) ([]byte, bool) {
+	if field := d.standardObject(objectId); field != nil {
+		return []byte(*field), *field != ""
+	}
+	value, ok := d.PrivateObjects[objectId]
+	return value, ok && objectId >= DeviceObjectPrivateStart
+}
+
+// SetObject 设置对象 ID 对应的值，0x07-0x7F 为保留对象，不可设置
+func (d *DeviceIdentification) SetObject(objectId byte, value []byte) error {
+	if field := d.standardObject(objectId); field != nil {
+		*field = string(value)
+		return nil
+	}
+	if objectId < DeviceObjectPrivateStart {
+		return fmt.Errorf("modbus: device object id '%v' is reserved", objectId)
+	}
+	if d.PrivateObjects == nil {
+		d.PrivateObjects = make(map[byte][]byte)
+	}
+	d.PrivateObjects[objectId] = append([]byte(nil), value...)
+	return nil
+}
+
+// ObjectIds 返回已配置的对象 ID，按升序排列
+func (d *DeviceIdentification) ObjectIds() []byte {
+	var ids []byte
+	for id := DeviceObjectVendorName; id <= DeviceObjectUserApplicationName; id++ {
+		if _, ok := d.Object(id); ok {
+			ids = append(ids, id)
+		}
+	}
+	private := make([]byte, 0, len(d.PrivateObjects))
+	for id := range d.PrivateObjects {
+		if id >= DeviceObjectPrivateStart {
+			private = append(private, id)
+		}
+	}
+	sort.Slice(private, func(i, j int) bool { return private[i] < private[j] })
+	return append(ids, private...)
+}
+
+// Conformity 根据已配置的对象计算一致性等级，始终支持读取单个对象
+func (d *DeviceIdentification) Conformity() byte {
+	level := ConformityLevelBasic
+	for _, id := range d.ObjectIds() {
+		if id >= DeviceObjectPrivateStart {
+			level = ConformityLevelExtended
+			break
+		}
+		if id > DeviceObjectProductVersion {
+			level = ConformityLevelRegular
+		}
+	}
+	return level | ConformityLevelIndividualAccess
+}
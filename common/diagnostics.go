@@ -0,0 +1,69 @@
+package common
+
+// Diagnostics (功能码 0x08) 子功能码
+const (
+	DiagnosticReturnQueryData                    uint16 = 0x00
+	DiagnosticRestartCommunicationsOption        uint16 = 0x01
+	DiagnosticReturnDiagnosticRegister           uint16 = 0x02
+	DiagnosticChangeASCIIInputDelimiter          uint16 = 0x03
+	DiagnosticForceListenOnlyMode                uint16 = 0x04
+	DiagnosticClearCountersAndDiagnosticRegister uint16 = 0x0A
+	DiagnosticReturnBusMessageCount              uint16 = 0x0B
+	DiagnosticReturnBusCommunicationErrorCount   uint16 = 0x0C
+	DiagnosticReturnBusExceptionErrorCount       uint16 = 0x0D
+	DiagnosticReturnServerMessageCount           uint16 = 0x0E
+	DiagnosticReturnServerNoResponseCount        uint16 = 0x0F
+	DiagnosticReturnServerNAKCount               uint16 = 0x10
+	DiagnosticReturnServerBusyCount              uint16 = 0x11
+	DiagnosticReturnBusCharacterOverrunCount     uint16 = 0x12
+	DiagnosticClearOverrunCounterAndFlag         uint16 = 0x14
+)
+
+// 通信事件日志 (Get Comm Event Log) 中的事件字节定义
+const (
+	// 接收事件，bit7 固定为 1
+	CommEventReceive                   byte = 0x80
+	CommEventReceiveCommunicationError byte = 0x02
+	CommEventReceiveCharacterOverrun   byte = 0x10
+	CommEventReceiveListenOnly         byte = 0x20
+	CommEventReceiveBroadcast          byte = 0x40
+	// 发送事件，bit7 为 0，bit6 为 1
+	CommEventSend                     byte = 0x40
+	CommEventSendReadException        byte = 0x01
+	CommEventSendServerAbortException byte = 0x02
+	CommEventSendServerBusyException  byte = 0x04
+	CommEventSendServerNAKException   byte = 0x08
+	CommEventSendWriteTimeout         byte = 0x10
+	CommEventSendListenOnly           byte = 0x20
+	// 进入仅监听模式
+	CommEventEnteredListenOnly byte = 0x04
+	// 通信重启
+	CommEventCommunicationRestart byte = 0x00
+)
+
+// CommEventStatusBusy 状态字，表示从站仍在处理之前的程序命令
+const CommEventStatusBusy uint16 = 0xFFFF
+
+// CommEventCounter Get Comm Event Counter (功能码 0x0B) 的响应
+type CommEventCounter struct {
+	Status     uint16 // 状态字
+	EventCount uint16 // 事件计数
+}
+
+// Busy 从站是否忙
+func (c *CommEventCounter) Busy() bool {
+	return c.Status == CommEventStatusBusy
+}
+
+// CommEventLog Get Comm Event Log (功能码 0x0C) 的响应
+type CommEventLog struct {
+	Status       uint16 // 状态字
+	EventCount   uint16 // 事件计数
+	MessageCount uint16 // 总线报文计数
+	Events       []byte // 事件，最新的在前
+}
+
+// Busy 从站是否忙
+func (l *CommEventLog) Busy() bool {
+	return l.Status == CommEventStatusBusy
+}
@@ -0,0 +1,96 @@
+package common
+
+import "fmt"
+
+// Enron/Daniel Modbus 扩展中 32 位寄存器单次请求的最大数量，受 PDU 长度限制
+const (
+	MaxEnronReadRegisters  = 62
+	MaxEnronWriteRegisters = 61
+)
+
+// EnronRange Enron/Daniel Modbus 扩展中的 32 位寄存器地址区间，Start 和 End 均包含在内
+type EnronRange struct {
+	Start uint16
+	End   uint16
+}
+
+// EnronLayout Enron/Daniel Modbus 扩展的寄存器布局，落在 Ranges 中的保持寄存器每个为 32 位，
+// 功能码 0x03、0x06、0x10 的寄存器数量表示 32 位值的个数，其余地址为标准 16 位寄存器
+type EnronLayout struct {
+	Ranges []EnronRange
+	// Order 32 位值的字节序，默认为 ABCD
+	Order ByteOrder
+}
+
+// DefaultEnronLayout 返回常用的 Enron/Daniel 布局，5001-5999 为 32 位整数，7001-7999 为 32 位浮点数
+func DefaultEnronLayout() *EnronLayout {
+	return &EnronLayout{
+		Ranges: []EnronRange{
+			{Start: 5001, End: 5999},
+			{Start: 7001, End: 7999},
+		},
+	}
+}
+
+// Is32Bit 判断地址是否为 32 位寄存器，l 为 nil 时均为 16 位
+func (l *EnronLayout) Is32Bit(address uint16) bool {
+	_, ok := l.rangeOf(address)
+	return ok
+}
+
+// Width 返回从 address 开始 quantity 个寄存器的每个寄存器字节数（2 或 4），
+// 请求跨越 32 位区间的边界时返回错误
+func (l *EnronLayout) Width(address uint16, quantity uint16) (int, error) {
+	if quantity == 0 {
+		quantity = 1
+	}
+	last := int(address) + int(quantity) - 1
+	if r, ok := l.rangeOf(address); ok {
+		if last > int(r.End) {
+			return 0, fmt.Errorf("modbus: address range '%v' + '%v' crosses 32-bit range end '%v'", address, quantity, r.End)
+		}
+		return 4, nil
+	}
+	if l != nil {
+		for _, r := range l.Ranges {
+			if int(r.Start) > int(address) && int(r.Start) <= last {
+				return 0, fmt.Errorf("modbus: address range '%v' + '%v' crosses 32-bit range start '%v'", address, quantity, r.Start)
+			}
+		}
+	}
+	return 2, nil
+}
+
+// AppendValues 将 32 位值按字节序编码后追加到 dst
+func (l *EnronLayout) AppendValues(dst []byte, values []uint32) []byte {
+	words := EncodeWords(values, l.order())
+	return WordsToBytes(dst, words)
+}
+
+// DecodeValues 将字节按字节序解码为 32 位值，长度必须是 4 的整数倍
+func (l *EnronLayout) DecodeValues(data []byte) ([]uint32, error) {
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("modbus: data length '%v' is not a multiple of '%v'", len(data), 4)
+	}
+	return DecodeWords[uint32](BytesToWords(make([]uint16, 0, len(data)/2), data), l.order())
+}
+
+// rangeOf 返回地址所在的 32 位区间
+func (l *EnronLayout) rangeOf(address uint16) (EnronRange, bool) {
+	if l == nil {
+		return EnronRange{}, false
+	}
+	for _, r := range l.Ranges {
+		if address >= r.Start && address <= r.End {
+			return r, true
+		}
+	}
+	return EnronRange{}, false
+}
+
+func (l *EnronLayout) order() ByteOrder {
+	if l == nil {
+		return ByteOrderABCD
+	}
+	return l.Order
+}
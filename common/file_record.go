@@ -0,0 +1,21 @@
+package common
+
+// FileRecordReferenceType 文件记录访问的参考类型，规范要求固定为 6
+const FileRecordReferenceType = 0x06
+
+// FileRecordMaxRecordNumber 文件内记录号的最大值 (0x270F)
+const FileRecordMaxRecordNumber = 9999
+
+// FileRecordRequest 读文件记录的子请求
+type FileRecordRequest struct {
+	FileNumber   uint16 // 文件号
+	RecordNumber uint16 // 起始记录号
+	RecordLength uint16 // 记录数量（寄存器个数）
+}
+
+// FileRecord 文件记录数据，读取时为子请求对应的返回数据，写入时为待写入的数据
+type FileRecord struct {
+	FileNumber   uint16
+	RecordNumber uint16
+	Registers    []*Register
+}
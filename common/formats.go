@@ -0,0 +1,244 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DecodeString 解析寄存器中的 ASCII/UTF-8 字符串，swapBytes 为 true 时字内低字节在前，去除末尾的 0x00 和空格
+func DecodeString(registers []*Register, swapBytes bool) string {
+	data := make([]byte, 0, len(registers)*2)
+	for _, register := range registers {
+		if swapBytes {
+			data = append(data, register.byte2, register.byte1)
+		} else {
+			data = append(data, register.byte1, register.byte2)
+		}
+	}
+	return strings.TrimRight(string(data), "\x00 ")
+}
+
+// EncodeString 将字符串编码为 quantity 个寄存器，不足部分以 0x00 填充
+func EncodeString(value string, quantity int, swapBytes bool) ([]*Register, error) {
+	if len(value) > quantity*2 {
+		return nil, fmt.Errorf("modbus: string length '%v' exceeds '%v' bytes", len(value), quantity*2)
+	}
+	data := make([]byte, quantity*2)
+	copy(data, value)
+	registers := NewRegisters(data)
+	if swapBytes {
+		for _, register := range registers {
+			register.byte1, register.byte2 = register.byte2, register.byte1
+		}
+	}
+	return registers, nil
+}
+
+// DecodeBCD 解析压缩 BCD 码，每个寄存器 4 位十进制数，高位寄存器在前
+func DecodeBCD(registers []*Register) (value uint64, err error) {
+	if len(registers) < 1 || len(registers) > 4 {
+		err = fmt.Errorf("modbus: register count '%v' is out of range [1, 4]", len(registers))
+		return
+	}
+	for _, register := range registers {
+		word := register.Value()
+		for shift := 12; shift >= 0; shift -= 4 {
+			digit := (word >> shift) & 0x0F
+			if digit > 9 {
+				err = fmt.Errorf("modbus: register '%04x' is not a valid BCD value", word)
+				return 0, err
+			}
+			value = value*10 + uint64(digit)
+		}
+	}
+	return
+}
+
+// EncodeBCD 将数值编码为 quantity 个寄存器的压缩 BCD 码
+func EncodeBCD(value uint64, quantity int) ([]*Register, error) {
+	if quantity < 1 || quantity > 4 {
+		return nil, fmt.Errorf("modbus: register count '%v' is out of range [1, 4]", quantity)
+	}
+	words := make([]uint16, quantity)
+	for i := quantity - 1; i >= 0; i-- {
+		for shift := 0; shift < 16; shift += 4 {
+			words[i] |= uint16(value%10) << shift
+			value /= 10
+		}
+	}
+	if value != 0 {
+		return nil, fmt.Errorf("modbus: value exceeds '%v' BCD digits", quantity*4)
+	}
+	return NewRegistersFromUInt16s(words), nil
+}
+
+// DecodeSignedMagnitude 按字节序解析符号-幅值表示的整数，最高位为符号位，其余位为绝对值
+func DecodeSignedMagnitude(registers []*Register, order ByteOrder) (value int64, err error) {
+	n := len(registers)
+	if n < 1 || n > 4 {
+		err = fmt.Errorf("modbus: register count '%v' is out of range [1, 4]", n)
+		return
+	}
+	words := make([]uint16, n)
+	for i, register := range registers {
+		words[i] = register.Value()
+	}
+	bits := order.bits(words)
+	signBit := uint64(1) << (16*n - 1)
+	value = int64(bits &^ signBit)
+	if bits&signBit != 0 {
+		value = -value
+	}
+	return
+}
+
+// EncodeSignedMagnitude 将整数按字节序编码为 quantity 个寄存器的符号-幅值表示
+func EncodeSignedMagnitude(value int64, quantity int, order ByteOrder) ([]*Register, error) {
+	if quantity < 1 || quantity > 4 {
+		return nil, fmt.Errorf("modbus: register count '%v' is out of range [1, 4]", quantity)
+	}
+	magnitude := uint64(value)
+	if value < 0 {
+		magnitude = uint64(-value)
+	}
+	signBit := uint64(1) << (16*quantity - 1)
+	if magnitude >= signBit {
+		return nil, fmt.Errorf("modbus: value '%v' exceeds '%v' registers", value, quantity)
+	}
+	if value < 0 {
+		magnitude |= signBit
+	}
+	registers := make([]*Register, 0, quantity)
+	for _, word := range order.appendWords(nil, magnitude, quantity) {
+		registers = append(registers, NewRegisterFromUInt16(word))
+	}
+	return registers, nil
+}
+
+// Bits 返回寄存器从 offset 位开始的 width 位（bit 0 为最低位），位段超出 16 位时返回错误
+func (r *Register) Bits(offset, width uint) (value uint16, err error) {
+	if err = checkBitField(offset, width); err != nil {
+		return
+	}
+	value = (r.Value() >> offset) & uint16(1<<width-1)
+	return
+}
+
+// SetBits 设置寄存器从 offset 位开始的 width 位（bit 0 为最低位），value 超出部分被忽略，位段超出 16 位时返回错误
+func (r *Register) SetBits(offset, width uint, value uint16) error {
+	if err := checkBitField(offset, width); err != nil {
+		return err
+	}
+	mask := uint16(1<<width-1) << offset
+	word := r.Value()&^mask | (value<<offset)&mask
+	r.byte1, r.byte2 = byte(word>>8), byte(word)
+	return nil
+}
+
+// checkBitField 检查位段是否在寄存器的 16 位之内
+func checkBitField(offset, width uint) error {
+	if width == 0 || offset+width > 16 {
+		return fmt.Errorf("modbus: bit field offset '%v' width '%v' is out of range '%v'", offset, width, 16)
+	}
+	return nil
+}
+
+// RegistersToBitVector 将寄存器展开为位向量，每个寄存器 16 位，bit 0 为最低位
+func RegistersToBitVector(registers []*Register) *BitVector {
+	bv := NewBitVector(uint(len(registers)) * 16)
+	for i, register := range registers {
+		word := register.Value()
+		for bit := uint(0); bit < 16; bit++ {
+			bv.Set(uint(i)*16+bit, word&(1<<bit) != 0)
+		}
+	}
+	return bv
+}
+
+// BitVectorToRegisters 将位向量按每 16 位打包为寄存器，不足 16 位的部分补 0
+func BitVectorToRegisters(bv *BitVector) []*Register {
+	registers := make([]*Register, (bv.Size()+15)/16)
+	for i := range registers {
+		var word uint16
+		for bit := uint(0); bit < 16 && uint(i)*16+bit < bv.Size(); bit++ {
+			if bv.Get(uint(i)*16 + bit) {
+				word |= 1 << bit
+			}
+		}
+		registers[i] = NewRegisterFromUInt16(word)
+	}
+	return registers
+}
+
+// DecodeUnixTime 按字节序解析 Unix 时间戳（秒），2 个寄存器为 32 位无符号数，4 个寄存器为 64 位有符号数
+func DecodeUnixTime(registers []*Register, order ByteOrder) (t time.Time, err error) {
+	switch len(registers) {
+	case 2:
+		values, e := DecodeRegisters[uint32](registers, order)
+		if e != nil {
+			return t, e
+		}
+		return time.Unix(int64(values[0]), 0), nil
+	case 4:
+		values, e := DecodeRegisters[int64](registers, order)
+		if e != nil {
+			return t, e
+		}
+		return time.Unix(values[0], 0), nil
+	}
+	err = fmt.Errorf("modbus: register count '%v' is neither '%v' nor '%v'", len(registers), 2, 4)
+	return
+}
+
+// EncodeUnixTime 按字节序将时间编码为 Unix 时间戳（秒），quantity 为 2 (32 位) 或 4 (64 位)
+func EncodeUnixTime(t time.Time, quantity int, order ByteOrder) ([]*Register, error) {
+	seconds := t.Unix()
+	switch quantity {
+	case 2:
+		if seconds < 0 || seconds > 0xFFFFFFFF {
+			return nil, fmt.Errorf("modbus: time '%v' does not fit in 32 bits", t)
+		}
+		return EncodeRegisters([]uint32{uint32(seconds)}, order), nil
+	case 4:
+		return EncodeRegisters([]int64{seconds}, order), nil
+	}
+	return nil, fmt.Errorf("modbus: register count '%v' is neither '%v' nor '%v'", quantity, 2, 4)
+}
+
+// DateTimeRegisterCount 分解时间占用的寄存器数量：年 月 日 时 分 秒
+const DateTimeRegisterCount = 6
+
+// DecodeDateTime 解析按 年(完整年份) 月 日 时 分 秒 顺序存放的 6 个寄存器，loc 为设备所在时区
+func DecodeDateTime(registers []*Register, loc *time.Location) (t time.Time, err error) {
+	if len(registers) != DateTimeRegisterCount {
+		err = fmt.Errorf("modbus: register count '%v' does not match expected '%v'", len(registers), DateTimeRegisterCount)
+		return
+	}
+	var fields [DateTimeRegisterCount]int
+	for i, register := range registers {
+		fields[i] = int(register.Value())
+	}
+	year, month, day, hour, minute, second := fields[0], fields[1], fields[2], fields[3], fields[4], fields[5]
+	if month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || minute > 59 || second > 59 {
+		err = fmt.Errorf("modbus: date time '%v' is invalid", fields)
+		return
+	}
+	t = time.Date(year, time.Month(month), day, hour, minute, second, 0, loc)
+	if t.Day() != day {
+		err = fmt.Errorf("modbus: date time '%v' is invalid", fields)
+		return time.Time{}, err
+	}
+	return
+}
+
+// EncodeDateTime 将时间按 年 月 日 时 分 秒 编码为 6 个寄存器，时间会先转换到 loc 时区
+func EncodeDateTime(t time.Time, loc *time.Location) []*Register {
+	t = t.In(loc)
+	fields := []int{t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second()}
+	registers := make([]*Register, len(fields))
+	for i, field := range fields {
+		registers[i] = NewRegisterFromUInt16(uint16(field))
+	}
+	return registers
+}
@@ -0,0 +1,242 @@
+package common
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStringCodec(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		quantity  int
+		swapBytes bool
+		words     []uint16
+		decoded   string
+		wantErr   bool
+	}{
+		{name: "padded", value: "ABC", quantity: 3, words: []uint16{0x4142, 0x4300, 0x0000}, decoded: "ABC"},
+		{name: "swapped", value: "ABC", quantity: 2, swapBytes: true, words: []uint16{0x4241, 0x0043}, decoded: "ABC"},
+		{name: "exact length", value: "ABCD", quantity: 2, words: []uint16{0x4142, 0x4344}, decoded: "ABCD"},
+		{name: "trailing spaces trimmed", value: "AB  ", quantity: 2, words: []uint16{0x4142, 0x2020}, decoded: "AB"},
+		{name: "too long", value: "ABCDE", quantity: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registers, err := EncodeString(tt.value, tt.quantity, tt.swapBytes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("encode error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if words := RegisterValues(registers); !slices.Equal(words, tt.words) {
+				t.Fatalf("encode = %04X, want %04X", words, tt.words)
+			}
+			if value := DecodeString(registers, tt.swapBytes); value != tt.decoded {
+				t.Fatalf("decode = %q, want %q", value, tt.decoded)
+			}
+		})
+	}
+}
+
+func TestBCD(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    uint64
+		quantity int
+		words    []uint16
+		wantErr  bool
+	}{
+		{name: "one register", value: 1234, quantity: 1, words: []uint16{0x1234}},
+		{name: "leading zeros", value: 56, quantity: 2, words: []uint16{0x0000, 0x0056}},
+		{name: "four registers", value: 9999999999999999, quantity: 4, words: []uint16{0x9999, 0x9999, 0x9999, 0x9999}},
+		{name: "overflow", value: 10000, quantity: 1, wantErr: true},
+		{name: "zero registers", value: 1, quantity: 0, wantErr: true},
+		{name: "five registers", value: 1, quantity: 5, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registers, err := EncodeBCD(tt.value, tt.quantity)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("encode error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if words := RegisterValues(registers); !slices.Equal(words, tt.words) {
+				t.Fatalf("encode = %04X, want %04X", words, tt.words)
+			}
+			if value, err := DecodeBCD(registers); err != nil || value != tt.value {
+				t.Fatalf("decode = %v, %v, want %v", value, err, tt.value)
+			}
+		})
+	}
+
+	for _, words := range [][]uint16{{0x123A}, {0x0001, 0xA000}, {}, {1, 2, 3, 4, 5}} {
+		if _, err := DecodeBCD(NewRegistersFromUInt16s(words)); err == nil {
+			t.Errorf("decode %04X: got nil error", words)
+		}
+	}
+}
+
+func TestSignedMagnitude(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    int64
+		quantity int
+		order    ByteOrder
+		words    []uint16
+		wantErr  bool
+	}{
+		{name: "positive", value: 5, quantity: 1, order: ByteOrderABCD, words: []uint16{0x0005}},
+		{name: "negative", value: -5, quantity: 1, order: ByteOrderABCD, words: []uint16{0x8005}},
+		{name: "largest magnitude", value: -0x7FFF, quantity: 1, order: ByteOrderABCD, words: []uint16{0xFFFF}},
+		{name: "word swapped", value: -0x10002, quantity: 2, order: ByteOrderCDAB, words: []uint16{0x0002, 0x8001}},
+		{name: "byte swapped", value: -1, quantity: 2, order: ByteOrderBADC, words: []uint16{0x0080, 0x0100}},
+		{name: "sign bit limit", value: 0x8000, quantity: 1, order: ByteOrderABCD, wantErr: true},
+		{name: "negative sign bit limit", value: -0x8000, quantity: 1, order: ByteOrderABCD, wantErr: true},
+		{name: "minimum int64", value: math.MinInt64, quantity: 4, order: ByteOrderABCD, wantErr: true},
+		{name: "zero registers", value: 1, quantity: 0, order: ByteOrderABCD, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registers, err := EncodeSignedMagnitude(tt.value, tt.quantity, tt.order)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("encode error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if words := RegisterValues(registers); !slices.Equal(words, tt.words) {
+				t.Fatalf("encode = %04X, want %04X", words, tt.words)
+			}
+			if value, err := DecodeSignedMagnitude(registers, tt.order); err != nil || value != tt.value {
+				t.Fatalf("decode = %v, %v, want %v", value, err, tt.value)
+			}
+		})
+	}
+
+	// 负零解析为 0
+	if value, err := DecodeSignedMagnitude(NewRegistersFromUInt16s([]uint16{0x8000}), ByteOrderABCD); err != nil || value != 0 {
+		t.Fatalf("negative zero = %v, %v", value, err)
+	}
+}
+
+func TestRegisterBits(t *testing.T) {
+	tests := []struct {
+		name    string
+		offset  uint
+		width   uint
+		value   uint16
+		want    uint16
+		wantErr bool
+	}{
+		{name: "low nibble", offset: 0, width: 4, value: 0xF, want: 0xA5AF},
+		{name: "middle", offset: 4, width: 8, value: 0x3C, want: 0xA3C5},
+		{name: "value truncated", offset: 12, width: 4, value: 0x1B, want: 0xB5A5},
+		{name: "whole register", offset: 0, width: 16, value: 0x1234, want: 0x1234},
+		{name: "top bit", offset: 15, width: 1, value: 0, want: 0x25A5},
+		{name: "zero width", offset: 0, width: 0, wantErr: true},
+		{name: "past bit 15", offset: 15, width: 2, wantErr: true},
+		{name: "offset 16", offset: 16, width: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			register := NewRegisterFromUInt16(0xA5A5)
+			err := register.SetBits(tt.offset, tt.width, tt.value)
+			if _, bitsErr := register.Bits(tt.offset, tt.width); (err != nil) != tt.wantErr || (bitsErr != nil) != tt.wantErr {
+				t.Fatalf("errors = %v, %v, want error %v", err, bitsErr, tt.wantErr)
+			}
+			if tt.wantErr {
+				if register.Value() != 0xA5A5 {
+					t.Fatalf("register = %04X, want unchanged", register.Value())
+				}
+				return
+			}
+			if register.Value() != tt.want {
+				t.Fatalf("register = %04X, want %04X", register.Value(), tt.want)
+			}
+			if bits, _ := register.Bits(tt.offset, tt.width); bits != tt.value&uint16(1<<tt.width-1) {
+				t.Fatalf("bits = %X", bits)
+			}
+		})
+	}
+}
+
+func TestDecodeDateTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  []uint16
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "valid", fields: []uint16{2024, 2, 29, 23, 59, 59}, want: time.Date(2024, 2, 29, 23, 59, 59, 0, time.UTC)},
+		{name: "feb 30", fields: []uint16{2024, 2, 30, 0, 0, 0}, wantErr: true},
+		{name: "feb 29 in common year", fields: []uint16{2023, 2, 29, 0, 0, 0}, wantErr: true},
+		{name: "month 13", fields: []uint16{2024, 13, 1, 0, 0, 0}, wantErr: true},
+		{name: "hour 24", fields: []uint16{2024, 1, 1, 24, 0, 0}, wantErr: true},
+		{name: "five registers", fields: []uint16{2024, 1, 1, 0, 0}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeDateTime(NewRegistersFromUInt16s(tt.fields), time.UTC)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("time = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 编码时先转换到设备时区
+	loc := time.FixedZone("UTC+8", 8*3600)
+	registers := EncodeDateTime(time.Date(2024, 12, 31, 20, 30, 0, 0, time.UTC), loc)
+	if words := RegisterValues(registers); !slices.Equal(words, []uint16{2025, 1, 1, 4, 30, 0}) {
+		t.Fatalf("encode = %v", words)
+	}
+}
+
+func TestUnixTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		time     time.Time
+		quantity int
+		order    ByteOrder
+		words    []uint16
+		wantErr  bool
+	}{
+		{name: "32 bits", time: time.Unix(0x12345678, 0), quantity: 2, order: ByteOrderABCD, words: []uint16{0x1234, 0x5678}},
+		{name: "32 bits word swapped", time: time.Unix(0x12345678, 0), quantity: 2, order: ByteOrderCDAB, words: []uint16{0x5678, 0x1234}},
+		{name: "32 bits maximum", time: time.Unix(0xFFFFFFFF, 0), quantity: 2, order: ByteOrderABCD, words: []uint16{0xFFFF, 0xFFFF}},
+		{name: "64 bits", time: time.Unix(0x123456789A, 0), quantity: 4, order: ByteOrderABCD, words: []uint16{0x0000, 0x0012, 0x3456, 0x789A}},
+		{name: "64 bits before epoch", time: time.Unix(-1, 0), quantity: 4, order: ByteOrderDCBA, words: []uint16{0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF}},
+		{name: "32 bits before epoch", time: time.Unix(-1, 0), quantity: 2, order: ByteOrderABCD, wantErr: true},
+		{name: "32 bits overflow", time: time.Unix(0x100000000, 0), quantity: 2, order: ByteOrderABCD, wantErr: true},
+		{name: "3 registers", time: time.Unix(0, 0), quantity: 3, order: ByteOrderABCD, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registers, err := EncodeUnixTime(tt.time, tt.quantity, tt.order)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("encode error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if words := RegisterValues(registers); !slices.Equal(words, tt.words) {
+				t.Fatalf("encode = %04X, want %04X", words, tt.words)
+			}
+			if decoded, err := DecodeUnixTime(registers, tt.order); err != nil || !decoded.Equal(tt.time) {
+				t.Fatalf("decode = %v, %v, want %v", decoded, err, tt.time)
+			}
+		})
+	}
+
+	if _, err := DecodeUnixTime(NewRegistersFromUInt16s([]uint16{1, 2, 3}), ByteOrderABCD); err == nil {
+		t.Fatal("decode 3 registers: got nil error")
+	}
+}
@@ -0,0 +1,22 @@
+package common
+
+// LRC Modbus ASCII 帧使用的纵向冗余校验，为所有字节之和的二进制补码
+type LRC struct {
+	sum byte
+}
+
+func (l *LRC) Reset() *LRC {
+	l.sum = 0
+	return l
+}
+
+func (l *LRC) PushBytes(bs []byte) *LRC {
+	for _, b := range bs {
+		l.sum += b
+	}
+	return l
+}
+
+func (l *LRC) Value() byte {
+	return -l.sum
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sync"
 )
 
 const (
@@ -13,28 +14,59 @@ const (
 	tcpMaxLength                  = 260
 )
 
+// mbapBuffers ReadFromConn 使用的帧缓冲池
+var mbapBuffers = sync.Pool{
+	New: func() any { return new([tcpMaxLength]byte) },
+}
+
 type MBAPFrame struct {
 	TransactionId uint16
 	ProtocolId    uint16
 	Length        uint16
 	UnitId        byte
 	PDU           *ProtocolDataUnit
+
+	buffer *[tcpMaxLength]byte // ReadFromConn 从缓冲池取得的缓冲区
 }
 
 func (f *MBAPFrame) ToBytes() []byte {
-	messageData := make([]byte, mbapHeaderSize+1+len(f.PDU.Data))
-	binary.BigEndian.PutUint16(messageData, f.TransactionId)
-	binary.BigEndian.PutUint16(messageData[2:], f.ProtocolId)
-	length := uint16(1 + 1 + len(f.PDU.Data))
-	binary.BigEndian.PutUint16(messageData[4:], length)
-	messageData[6] = f.UnitId
-	copy(messageData[mbapHeaderSize:], f.PDU.ToBytes())
-	return messageData
+	return f.AppendBytes(make([]byte, 0, mbapHeaderSize+1+len(f.PDU.Data)))
+}
+
+// AppendBytes 将帧编码后追加到 dst，dst 容量足够时不分配内存
+func (f *MBAPFrame) AppendBytes(dst []byte) []byte {
+	dst = binary.BigEndian.AppendUint16(dst, f.TransactionId)
+	dst = binary.BigEndian.AppendUint16(dst, f.ProtocolId)
+	dst = binary.BigEndian.AppendUint16(dst, uint16(1+1+len(f.PDU.Data)))
+	dst = append(dst, f.UnitId, f.PDU.FunctionCode)
+	return append(dst, f.PDU.Data...)
 }
 
+// ReadFromConn 从连接读取一帧，PDU.Data 引用缓冲池中的缓冲区，使用完毕后应调用 Release 归还
 func (f *MBAPFrame) ReadFromConn(conn net.Conn) error {
+	f.Release()
+	buffer := mbapBuffers.Get().(*[tcpMaxLength]byte)
+	if err := f.readFrom(conn, buffer[:]); err != nil {
+		mbapBuffers.Put(buffer)
+		return err
+	}
+	f.buffer = buffer
+	return nil
+}
+
+// Release 将 ReadFromConn 使用的缓冲区归还缓冲池，之后不能再访问 PDU.Data
+func (f *MBAPFrame) Release() {
+	if f.buffer == nil {
+		return
+	}
+	mbapBuffers.Put(f.buffer)
+	f.buffer = nil
+	f.PDU = nil
+}
+
+// readFrom 使用 data 作为缓冲区读取一帧
+func (f *MBAPFrame) readFrom(conn net.Conn, data []byte) error {
 	// 读取 MBAP 头
-	var data [tcpMaxLength]byte
 	if _, err := io.ReadFull(conn, data[:mbapHeaderSize]); err != nil {
 		return err
 	}
@@ -72,6 +104,10 @@ func NewMBAPFrame(transactionId uint16, slaveId byte, pdu *ProtocolDataUnit) *MB
 }
 
 func NewMBAPFrameFromBytes(messageData []byte) (frame *MBAPFrame, err error) {
+	if len(messageData) <= mbapHeaderSize {
+		err = fmt.Errorf("modbus: message length '%v' must be greater than header size '%v'", len(messageData), mbapHeaderSize)
+		return
+	}
 	transactionId := binary.BigEndian.Uint16(messageData[:2])
 	protocolId := binary.BigEndian.Uint16(messageData[2:4])
 	length := binary.BigEndian.Uint16(messageData[4:6])
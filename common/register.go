@@ -1,6 +1,9 @@
 package common
 
-import "fmt"
+import (
+	"encoding/binary"
+	"fmt"
+)
 
 type Register struct {
 	byte1 byte
@@ -30,14 +33,52 @@ func NewRegisters(buffer []byte) []*Register {
 }
 
 func RegistersToBytes(registers []*Register) *[]byte {
-	value := make([]byte, len(registers)*2)
-	for i := 0; i < len(registers); i++ {
-		value[i*2] = registers[i].byte1
-		value[i*2+1] = registers[i].byte2
-	}
+	value := AppendRegisterBytes(make([]byte, 0, len(registers)*2), registers)
 	return &value
 }
 
+// AppendRegisterBytes 将寄存器按大端编码追加到 dst
+func AppendRegisterBytes(dst []byte, registers []*Register) []byte {
+	for _, register := range registers {
+		dst = append(dst, register.byte1, register.byte2)
+	}
+	return dst
+}
+
+// NewRegistersFromUInt16s 由寄存器值创建寄存器
+func NewRegistersFromUInt16s(values []uint16) []*Register {
+	registers := make([]*Register, len(values))
+	for i, value := range values {
+		registers[i] = NewRegisterFromUInt16(value)
+	}
+	return registers
+}
+
+// RegisterValues 返回寄存器的值
+func RegisterValues(registers []*Register) []uint16 {
+	values := make([]uint16, len(registers))
+	for i, register := range registers {
+		values[i] = register.Value()
+	}
+	return values
+}
+
+// BytesToWords 将大端字节解码为寄存器值追加到 dst，dst 容量足够时不分配内存
+func BytesToWords(dst []uint16, data []byte) []uint16 {
+	for i := 0; i+1 < len(data); i += 2 {
+		dst = append(dst, binary.BigEndian.Uint16(data[i:]))
+	}
+	return dst
+}
+
+// WordsToBytes 将寄存器值按大端编码追加到 dst，dst 容量足够时不分配内存
+func WordsToBytes(dst []byte, words []uint16) []byte {
+	for _, word := range words {
+		dst = binary.BigEndian.AppendUint16(dst, word)
+	}
+	return dst
+}
+
 func (r *Register) ToHexString() string {
 	return fmt.Sprintf("%02x%02x", r.byte1, r.byte2)
 }
@@ -4,8 +4,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
-	"net"
-	"time"
+	"sync"
 )
 
 const (
@@ -14,35 +13,61 @@ const (
 	rtuExceptionSize = 5
 )
 
+// rtuBuffers ReadFromConn 使用的帧缓冲池
+var rtuBuffers = sync.Pool{
+	New: func() any { return new([rtuMaxSize]byte) },
+}
+
 type RTUFrame struct {
 	SlaveId byte
 	PDU     *ProtocolDataUnit
 	CRC     *CRC
+	// ResponseLength ReadFromConn 计算响应长度时优先使用的函数，为 nil 时只使用内置的计算
+	ResponseLength ResponseLengthFunc
+
+	buffer *[rtuMaxSize]byte // ReadFromConn 从缓冲池取得的缓冲区
 }
 
 func (f *RTUFrame) ToBytes() []byte {
-	length := len(f.PDU.Data) + 4
-	messageData := make([]byte, length)
+	return f.AppendBytes(make([]byte, 0, len(f.PDU.Data)+4))
+}
 
-	messageData[0] = f.SlaveId
-	copy(messageData[1:], f.PDU.ToBytes())
+// AppendBytes 将帧编码后追加到 dst，dst 容量足够时不分配内存
+func (f *RTUFrame) AppendBytes(dst []byte) []byte {
+	start := len(dst)
+	dst = append(dst, f.SlaveId, f.PDU.FunctionCode)
+	dst = append(dst, f.PDU.Data...)
 
 	// Append crc
 	crc := CRC{}
-	crc.Reset().PushBytes(messageData[0 : length-2])
-	copy(messageData[length-2:], crc.SumBytes())
-	return messageData
+	crc.Reset().PushBytes(dst[start:])
+	return append(dst, crc.SumBytes()...)
+}
+
+// ReadFromConn 从连接读取 requestData 对应的响应帧，PDU.Data 引用缓冲池中的缓冲区，使用完毕后应调用 Release 归还
+func (f *RTUFrame) ReadFromConn(requestData []byte, conn io.Reader) error {
+	f.Release()
+	buffer := rtuBuffers.Get().(*[rtuMaxSize]byte)
+	if err := f.readFrom(requestData, conn, buffer[:]); err != nil {
+		rtuBuffers.Put(buffer)
+		return err
+	}
+	f.buffer = buffer
+	return nil
 }
 
-func (f *RTUFrame) ReadFromConn(requestData []byte, conn net.Conn) error {
-	bytesToRead := calculateResponseLength(requestData)
-	if bytesToRead > rtuMaxSize {
-		return fmt.Errorf("modbus: response length '%v' must not greater than '%v'", bytesToRead, rtuMaxSize)
+// Release 将 ReadFromConn 使用的缓冲区归还缓冲池，之后不能再访问 PDU.Data
+func (f *RTUFrame) Release() {
+	if f.buffer == nil {
+		return
 	}
-	//delay := calculateDelay(0, len(requestData)+bytesToRead)
-	//time.Sleep(delay)
+	rtuBuffers.Put(f.buffer)
+	f.buffer = nil
+	f.PDU = nil
+}
 
-	var data [rtuMaxSize]byte
+// readFrom 使用 data 作为缓冲区读取响应帧
+func (f *RTUFrame) readFrom(requestData []byte, conn io.Reader, data []byte) error {
 	if _, err := io.ReadFull(conn, data[:2]); err != nil {
 		return err
 	}
@@ -53,29 +78,46 @@ func (f *RTUFrame) ReadFromConn(requestData []byte, conn net.Conn) error {
 	if requestData[0] != f.SlaveId {
 		return fmt.Errorf("modbus: response slave id '%v' does not match request '%v'", data[0], requestData[0])
 	}
-	//正确返回
-	if f.PDU.FunctionCode == requestData[1] {
-		if _, err := io.ReadFull(conn, data[2:bytesToRead]); err != nil {
-			return err
-		}
-		crc := CRC{}
-		crc.Reset().PushBytes(data[0 : bytesToRead-2])
-		if !crc.Match(data[bytesToRead-2 : bytesToRead]) {
-			checksum := uint16(data[bytesToRead-1])<<8 | uint16(data[bytesToRead-2])
-			return fmt.Errorf("modbus: response crc '%v' does not match expected '%v'", checksum, crc.Value())
+	var bytesToRead int
+	switch f.PDU.FunctionCode {
+	case requestData[1]:
+		//正确返回，部分功能码需要根据已读取的数据逐步确定响应长度
+		read := 2
+		for {
+			length, err := responseLength(f.ResponseLength, requestData, data[:read])
+			if err != nil {
+				return err
+			}
+			bytesToRead = length
+			if bytesToRead > rtuMaxSize {
+				return fmt.Errorf("modbus: response length '%v' must not greater than '%v'", bytesToRead, rtuMaxSize)
+			}
+			if bytesToRead <= read {
+				break
+			}
+			if _, err := io.ReadFull(conn, data[read:bytesToRead]); err != nil {
+				return err
+			}
+			read = bytesToRead
 		}
-		f.PDU.Data = data[2 : bytesToRead-2]
-		f.CRC = &crc
-	} else if f.PDU.FunctionCode == requestData[1]&0x80 {
+	case requestData[1] | 0x80:
 		//返回异常
-		if _, err := io.ReadFull(conn, data[2:rtuExceptionSize]); err != nil {
+		bytesToRead = rtuExceptionSize
+		if _, err := io.ReadFull(conn, data[2:bytesToRead]); err != nil {
 			return err
 		}
-		f.PDU.Data = data[2:rtuExceptionSize]
-	} else {
+	default:
 		return fmt.Errorf("modbus: response function '%v' does not match request '%v'", data[1], requestData[1])
 	}
 
+	crc := CRC{}
+	crc.Reset().PushBytes(data[0 : bytesToRead-2])
+	if !crc.Match(data[bytesToRead-2 : bytesToRead]) {
+		checksum := uint16(data[bytesToRead-1])<<8 | uint16(data[bytesToRead-2])
+		return fmt.Errorf("modbus: response crc '%v' does not match expected '%v'", checksum, crc.Value())
+	}
+	f.PDU.Data = data[2 : bytesToRead-2]
+	f.CRC = &crc
 	return nil
 }
 
@@ -93,6 +135,10 @@ func NewRTUFrame(slaveId byte, pdu *ProtocolDataUnit) (frame *RTUFrame, err erro
 }
 func NewRTUFrameFromBytes(messageData []byte) (frame *RTUFrame, err error) {
 	length := len(messageData)
+	if length < rtuMinSize {
+		err = fmt.Errorf("modbus: message length '%v' does not meet minimum '%v'", length, rtuMinSize)
+		return
+	}
 	//calculated crc
 	crc := CRC{}
 	crc.Reset().PushBytes(messageData[0 : length-2])
@@ -112,20 +158,52 @@ func NewRTUFrameFromBytes(messageData []byte) (frame *RTUFrame, err error) {
 	return
 }
 
-func calculateDelay(baudRate, chars int) time.Duration {
-	var characterDelay, frameDelay int
-	//无效波特率
-	if baudRate <= 0 || baudRate > 19200 {
-		characterDelay = 750
-		frameDelay = 1750
-	} else {
-		characterDelay = 15000000 / baudRate
-		frameDelay = 35000000 / baudRate
-	}
-	return time.Duration(characterDelay*chars+frameDelay) * time.Microsecond
+// VerifyRTUResponse 校验 responseData 是否为 requestData 的完整响应帧：CRC 正确，从站地址和功能码一致，
+// 长度与按功能码计算的响应长度一致，用于数据报等以数据边界作为帧边界的传输。
+// lengthFunc 的用法同 RTUFrame.ResponseLength，响应长度无法确定时只校验 CRC、从站地址和功能码
+func VerifyRTUResponse(requestData []byte, responseData []byte, lengthFunc ResponseLengthFunc) error {
+	if _, err := NewRTUFrameFromBytes(responseData); err != nil {
+		return err
+	}
+	if responseData[0] != requestData[0] {
+		return fmt.Errorf("modbus: response slave id '%v' does not match request '%v'", responseData[0], requestData[0])
+	}
+	length := rtuExceptionSize
+	switch responseData[1] {
+	case requestData[1]:
+		var err error
+		if length, err = responseLength(lengthFunc, requestData, responseData); err != nil {
+			// 数据报的边界即为帧边界
+			return nil
+		}
+	case requestData[1] | 0x80:
+	default:
+		return fmt.Errorf("modbus: response function '%v' does not match request '%v'", responseData[1], requestData[1])
+	}
+	if len(responseData) != length {
+		return fmt.Errorf("modbus: response length '%v' does not match expected '%v'", len(responseData), length)
+	}
+	return nil
+}
+
+// ResponseLengthFunc 根据 RTU 请求帧和已读取的响应数据（至少包含从站地址和功能码）计算响应帧的总长度（含 CRC），
+// 返回值大于已读取的长度时会继续读取并再次调用，直至长度确定；返回 0 时使用内置的计算。
+// 用于内置计算无法确定响应长度的请求，如自定义功能码和 CANopen General Reference (MEI 类型 0x0D)
+type ResponseLengthFunc func(requestData []byte, responseData []byte) int
+
+// responseLength 优先使用 lengthFunc 计算响应帧的总长度，lengthFunc 为 nil 或返回 0 时使用内置的计算
+func responseLength(lengthFunc ResponseLengthFunc, requestData []byte, responseData []byte) (int, error) {
+	if lengthFunc != nil {
+		if length := lengthFunc(requestData, responseData); length > 0 {
+			return length, nil
+		}
+	}
+	return calculateResponseLength(requestData, responseData)
 }
 
-func calculateResponseLength(requestData []byte) int {
+// calculateResponseLength 根据请求和已读取的响应数据（至少包含从站地址和功能码）计算响应帧的总长度，
+// 返回值大于已读取的长度时需继续读取，读取后会再次调用，直至长度确定；功能码的响应长度未知时返回错误
+func calculateResponseLength(requestData []byte, responseData []byte) (int, error) {
 	length := rtuMinSize
 	switch requestData[1] {
 	case FuncCodeReadDiscreteInputs,
@@ -135,21 +213,62 @@ func calculateResponseLength(requestData []byte) int {
 		if count%8 != 0 {
 			length++
 		}
-	case FuncCodeReadInputRegisters,
-		FuncCodeReadHoldingRegisters,
-		FuncCodeReadWriteMultipleRegisters:
-		count := int(binary.BigEndian.Uint16(requestData[4:]))
-		length += (1 + count*2)
 	case FuncCodeWriteSingleCoil,
 		FuncCodeWriteMultipleCoils,
-		FuncCodeWriteSingleRegister,
 		FuncCodeWriteMultipleRegisters:
 		length += 4
+	case FuncCodeWriteSingleRegister:
+		// 正常响应为请求的原样返回，Enron 扩展的 32 位寄存器值为 4 字节
+		length = len(requestData)
 	case FuncCodeMaskWriteRegister:
 		length += 6
+	case FuncCodeReadExceptionStatus:
+		length += 1
+	case FuncCodeGetCommEventCounter:
+		length += 4
+	case FuncCodeDiagnostics,
+		FuncCodeWriteFileRecord:
+		// 正常响应为请求的原样返回
+		length = len(requestData)
+	case FuncCodeReadInputRegisters,
+		FuncCodeReadHoldingRegisters,
+		FuncCodeReadWriteMultipleRegisters,
+		FuncCodeReadFileRecord,
+		FuncCodeGetCommEventLog,
+		FuncCodeReportServerID:
+		// 从站地址，功能码，字节数，随后为字节数指定的数据，
+		// 寄存器读取按字节数计算，Enron 扩展的 32 位寄存器每个为 4 字节
+		if len(responseData) < 3 {
+			return 3, nil
+		}
+		length += 1 + int(responseData[2])
 	case FuncCodeReadFIFOQueue:
-		// undetermined
+		// 从站地址，功能码，字节数(2 bytes)，随后为字节数指定的数据
+		if len(responseData) < 4 {
+			return 4, nil
+		}
+		length += 2 + int(binary.BigEndian.Uint16(responseData[2:4]))
+	case FuncCodeReadDeviceIdentification:
+		// 设备标识以外的 MEI 类型（如 CANopen General Reference）的响应长度由具体数据决定
+		if len(requestData) > 2 && requestData[2] != MEITypeReadDeviceIdentification {
+			return 0, fmt.Errorf("modbus: response length of mei type '%v' is unknown, set a response length function", requestData[2])
+		}
+		// 从站地址，功能码，MEI类型，读取码，一致性等级，后续标识，下一对象ID，对象数量，
+		// 随后为对象ID、长度及数据，逐个对象读取长度
+		length = 8
+		if len(responseData) < length {
+			return length, nil
+		}
+		count := int(responseData[7])
+		for i := 0; i < count; i++ {
+			if len(responseData) < length+2 {
+				return length + 2, nil
+			}
+			length += 2 + int(responseData[length+1])
+		}
+		length += 2
 	default:
+		return 0, fmt.Errorf("modbus: response length of function code '%v' is unknown, set a response length function", requestData[1])
 	}
-	return length
+	return length, nil
 }
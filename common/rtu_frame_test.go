@@ -0,0 +1,136 @@
+package common
+
+import (
+	"bytes"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCalculateResponseLength(t *testing.T) {
+	readHolding := []byte{0x01, FuncCodeReadHoldingRegisters, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00}
+	readFIFO := []byte{0x01, FuncCodeReadFIFOQueue, 0x04, 0xDE, 0x00, 0x00}
+	readDeviceID := []byte{0x01, FuncCodeReadDeviceIdentification, MEITypeReadDeviceIdentification, 0x01, 0x00, 0x00, 0x00}
+	// 两个对象：ID 0 "abc"，ID 1 "x"
+	deviceID := []byte{0x01, FuncCodeReadDeviceIdentification, MEITypeReadDeviceIdentification, 0x01, 0x01, 0x00, 0x00, 0x02,
+		0x00, 0x03, 'a', 'b', 'c', 0x01, 0x01, 'x'}
+
+	tests := []struct {
+		name     string
+		request  []byte
+		response []byte
+		want     int
+		wantErr  bool
+	}{
+		{name: "read coils", request: []byte{0x01, FuncCodeReadCoils, 0x00, 0x00, 0x00, 0x0A, 0x00, 0x00}, response: []byte{0x01, FuncCodeReadCoils}, want: 7},
+		{name: "write single register echoes request", request: []byte{0x01, FuncCodeWriteSingleRegister, 0x00, 0x01, 0x00, 0x03, 0x00, 0x00}, response: []byte{0x01, FuncCodeWriteSingleRegister}, want: 8},
+		{name: "byte count not read", request: readHolding, response: []byte{0x01, FuncCodeReadHoldingRegisters}, want: 3},
+		{name: "byte count read", request: readHolding, response: []byte{0x01, FuncCodeReadHoldingRegisters, 0x04}, want: 9},
+		{name: "enron byte count", request: readHolding, response: []byte{0x01, FuncCodeReadHoldingRegisters, 0x08}, want: 13},
+		{name: "fifo byte count not read", request: readFIFO, response: []byte{0x01, FuncCodeReadFIFOQueue, 0x00}, want: 4},
+		{name: "fifo byte count read", request: readFIFO, response: []byte{0x01, FuncCodeReadFIFOQueue, 0x00, 0x06}, want: 12},
+		{name: "device id header not read", request: readDeviceID, response: deviceID[:2], want: 8},
+		{name: "device id first object header", request: readDeviceID, response: deviceID[:8], want: 10},
+		{name: "device id first object length", request: readDeviceID, response: deviceID[:10], want: 15},
+		{name: "device id second object header", request: readDeviceID, response: deviceID[:13], want: 15},
+		{name: "device id complete", request: readDeviceID, response: deviceID, want: 18},
+		{name: "canopen general reference", request: []byte{0x01, FuncCodeReadDeviceIdentification, MEITypeCANopenGeneralReference, 0x00}, response: []byte{0x01, FuncCodeReadDeviceIdentification}, wantErr: true},
+		{name: "unknown function code", request: []byte{0x01, 0x41, 0x00}, response: []byte{0x01, 0x41}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			length, err := calculateResponseLength(tt.request, tt.response)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got length %v, want error", length)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if length != tt.want {
+				t.Fatalf("length = %v, want %v", length, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponseLengthFunc(t *testing.T) {
+	custom := func(requestData []byte, responseData []byte) int {
+		if requestData[1] != 0x41 {
+			return 0
+		}
+		return 6
+	}
+	readHolding := []byte{0x01, FuncCodeReadHoldingRegisters, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00}
+
+	if length, err := responseLength(custom, []byte{0x01, 0x41, 0x00}, []byte{0x01, 0x41}); err != nil || length != 6 {
+		t.Fatalf("custom function code: got %v, %v, want 6", length, err)
+	}
+	// 返回 0 时使用内置的计算
+	if length, err := responseLength(custom, readHolding, []byte{0x01, FuncCodeReadHoldingRegisters, 0x02}); err != nil || length != 7 {
+		t.Fatalf("fallback: got %v, %v, want 7", length, err)
+	}
+	if _, err := responseLength(nil, []byte{0x01, 0x41, 0x00}, []byte{0x01, 0x41}); err == nil {
+		t.Fatal("nil function with unknown function code: got nil error")
+	}
+}
+
+// TestRTUFrameReadFromConn 逐字节读取时按逐步确定的长度读取完整帧，不读取后续数据
+func TestRTUFrameReadFromConn(t *testing.T) {
+	request := (&RTUFrame{SlaveId: 1, PDU: &ProtocolDataUnit{FunctionCode: FuncCodeReadFIFOQueue, Data: []byte{0x04, 0xDE}}}).ToBytes()
+	response := (&RTUFrame{SlaveId: 1, PDU: &ProtocolDataUnit{
+		FunctionCode: FuncCodeReadFIFOQueue,
+		Data:         []byte{0x00, 0x06, 0x00, 0x02, 0x01, 0xB8, 0x12, 0x84},
+	}}).ToBytes()
+	following := []byte{0xAA, 0xBB}
+	reader := bytes.NewReader(append(append([]byte{}, response...), following...))
+
+	frame := &RTUFrame{}
+	if err := frame.ReadFromConn(request, iotest.OneByteReader(reader)); err != nil {
+		t.Fatal(err)
+	}
+	defer frame.Release()
+	if want := response[2 : len(response)-2]; !bytes.Equal(frame.PDU.Data, want) {
+		t.Fatalf("data = % x, want % x", frame.PDU.Data, want)
+	}
+	if reader.Len() != len(following) {
+		t.Fatalf("unread = %v, want %v", reader.Len(), len(following))
+	}
+}
+
+func TestVerifyRTUResponse(t *testing.T) {
+	rtuFrame := func(slaveId byte, functionCode byte, data ...byte) []byte {
+		frame, err := NewRTUFrame(slaveId, &ProtocolDataUnit{FunctionCode: functionCode, Data: data})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return frame.ToBytes()
+	}
+	request := rtuFrame(0x01, FuncCodeReadHoldingRegisters, 0x00, 0x00, 0x00, 0x02)
+	corrupted := rtuFrame(0x01, FuncCodeReadHoldingRegisters, 0x04, 0x00, 0x01, 0x00, 0x02)
+	corrupted[len(corrupted)-1] ^= 0xFF
+
+	tests := []struct {
+		name     string
+		response []byte
+		wantErr  bool
+	}{
+		{name: "response", response: rtuFrame(0x01, FuncCodeReadHoldingRegisters, 0x04, 0x00, 0x01, 0x00, 0x02)},
+		{name: "exception", response: rtuFrame(0x01, FuncCodeReadHoldingRegisters|0x80, ExceptionCodeIllegalDataAddress)},
+		{name: "wrong slave id", response: rtuFrame(0x02, FuncCodeReadHoldingRegisters, 0x04, 0x00, 0x01, 0x00, 0x02), wantErr: true},
+		{name: "wrong function code", response: rtuFrame(0x01, FuncCodeReadInputRegisters, 0x04, 0x00, 0x01, 0x00, 0x02), wantErr: true},
+		{name: "exception of another function code", response: rtuFrame(0x01, FuncCodeReadInputRegisters|0x80, ExceptionCodeIllegalDataAddress), wantErr: true},
+		{name: "byte count does not match length", response: rtuFrame(0x01, FuncCodeReadHoldingRegisters, 0x04, 0x00, 0x01), wantErr: true},
+		{name: "exception with extra data", response: rtuFrame(0x01, FuncCodeReadHoldingRegisters|0x80, ExceptionCodeIllegalDataAddress, 0x00), wantErr: true},
+		{name: "bad crc", response: corrupted, wantErr: true},
+		{name: "too short", response: []byte{0x01, FuncCodeReadHoldingRegisters}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyRTUResponse(request, tt.response, nil); (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
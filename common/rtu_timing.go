@@ -0,0 +1,79 @@
+package common
+
+import (
+	"io"
+	"time"
+)
+
+// serialReadLatency 串口驱动和 USB 转换器成批交付接收数据引入的延迟，用户态按字符时间判断静默时需加上该值，
+// 否则一帧数据可能被拆成多帧
+const serialReadLatency = 20 * time.Millisecond
+
+// RTUCharTimeout 返回波特率对应的 1.5 字符时间，即 RTU 帧内两个字符的最大间隔，
+// 波特率无效或大于 19200 时固定为 750µs
+func RTUCharTimeout(baudRate int) time.Duration {
+	if baudRate <= 0 || baudRate > 19200 {
+		return 750 * time.Microsecond
+	}
+	return time.Duration(15000000/baudRate) * time.Microsecond
+}
+
+// RTUFrameDelay 返回波特率对应的 3.5 字符时间，即 RTU 帧之间总线的最小静默时间，
+// 波特率无效或大于 19200 时固定为 1750µs
+func RTUFrameDelay(baudRate int) time.Duration {
+	if baudRate <= 0 || baudRate > 19200 {
+		return 1750 * time.Microsecond
+	}
+	return time.Duration(35000000/baudRate) * time.Microsecond
+}
+
+// SerialRTUCharTimeout 返回在串口上读取 RTU 帧时使用的字符间超时，即 1.5 字符时间加上驱动交付数据的延迟
+func SerialRTUCharTimeout(baudRate int) time.Duration {
+	return RTUCharTimeout(baudRate) + serialReadLatency
+}
+
+// SerialRTUFrameTimeout 返回在串口上按静默划分 RTU 帧时使用的超时，即 3.5 字符时间加上驱动交付数据的延迟
+func SerialRTUFrameTimeout(baudRate int) time.Duration {
+	return RTUFrameDelay(baudRate) + serialReadLatency
+}
+
+// waitFrameDelay 等待到上一帧结束 delay 之后，保证两帧之间总线的静默时间
+func waitFrameDelay(lastFrame time.Time, delay time.Duration) {
+	if delay <= 0 || lastFrame.IsZero() {
+		return
+	}
+	if wait := time.Until(lastFrame.Add(delay)); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// charTimeoutReader 读到第一个字节后，每次读取前将截止时间设置为 timeout 之后，字符间隔超过 timeout 时读取返回错误
+type charTimeoutReader struct {
+	r        io.Reader
+	deadline interface{ SetReadDeadline(time.Time) error }
+	timeout  time.Duration
+	started  bool
+}
+
+// NewCharTimeoutReader 返回检查字符间隔的 Reader，第一个字节仍使用 r 原有的截止时间，
+// r 不支持 SetReadDeadline 或 timeout 不大于 0 时直接返回 r
+func NewCharTimeoutReader(r io.Reader, timeout time.Duration) io.Reader {
+	deadline, ok := r.(interface{ SetReadDeadline(time.Time) error })
+	if !ok || timeout <= 0 {
+		return r
+	}
+	return &charTimeoutReader{r: r, deadline: deadline, timeout: timeout}
+}
+
+func (r *charTimeoutReader) Read(p []byte) (n int, err error) {
+	if r.started {
+		if err = r.deadline.SetReadDeadline(time.Now().Add(r.timeout)); err != nil {
+			return
+		}
+	}
+	n, err = r.r.Read(p)
+	if n > 0 {
+		r.started = true
+	}
+	return
+}
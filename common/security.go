@@ -0,0 +1,74 @@
+package common
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+)
+
+// ModbusRoleOID Modbus/TCP Security 客户端证书中 Modbus Role 扩展的 OID，扩展值为 ASN.1 UTF8String
+var ModbusRoleOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 50316, 802, 1}
+
+// SecurePort Modbus/TCP Security 的默认端口
+const SecurePort = 802
+
+// ParseModbusRole 从证书的 Modbus Role 扩展中读取角色，证书没有该扩展时返回空字符串，
+// 扩展无法解析或出现多次时返回错误
+func ParseModbusRole(cert *x509.Certificate) (role string, err error) {
+	found := false
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(ModbusRoleOID) {
+			continue
+		}
+		if found {
+			err = fmt.Errorf("modbus: certificate has more than one role extension")
+			return
+		}
+		found = true
+		rest, e := asn1.UnmarshalWithParams(ext.Value, &role, "utf8")
+		if e != nil {
+			err = fmt.Errorf("modbus: parse role extension: %w", e)
+			return
+		}
+		if len(rest) > 0 {
+			err = fmt.Errorf("modbus: role extension has '%v' trailing bytes", len(rest))
+			return
+		}
+	}
+	return
+}
+
+// MarshalModbusRole 将角色编码为 Modbus Role 扩展，用于签发客户端证书
+func MarshalModbusRole(role string) (ext pkix.Extension, err error) {
+	value, err := asn1.MarshalWithParams(role, "utf8")
+	if err != nil {
+		return
+	}
+	ext = pkix.Extension{Id: ModbusRoleOID, Value: value}
+	return
+}
+
+type roleContextKey struct{}
+
+// WithRole 返回携带客户端 Modbus Role 的 context，从站处理函数通过 RoleFromContext 读取用于授权
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleContextKey{}, role)
+}
+
+// RoleFromContext 返回请求所在 TLS 连接的客户端 Modbus Role，非 TLS 连接返回 false
+func RoleFromContext(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(roleContextKey{}).(string)
+	return role, ok
+}
+
+// secureTLSConfig 复制 TLS 配置，并要求最低版本为 TLS 1.2
+func secureTLSConfig(config *tls.Config) *tls.Config {
+	config = config.Clone()
+	if config.MinVersion < tls.VersionTLS12 {
+		config.MinVersion = tls.VersionTLS12
+	}
+	return config
+}
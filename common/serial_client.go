@@ -0,0 +1,142 @@
+package common
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	serialTimeout     = 1 * time.Second
+	serialIdleTimeout = 60 * time.Second
+)
+
+// SerialClient 串口主站客户端，同一时刻只有一个请求在总线上
+type SerialClient struct {
+	Config      SerialConfig
+	Timeout     time.Duration
+	IdleTimeout time.Duration
+	// FrameDelay 上一帧在总线上结束后到发送下一个请求前的最小静默时间，
+	// NewSerialClient 按波特率设置为 3.5 字符时间，零值表示不等待
+	FrameDelay time.Duration
+
+	mu           sync.Mutex
+	port         *SerialPort
+	closeTimer   *time.Timer
+	lastActivity time.Time
+	lastFrame    time.Time // 上一帧在总线上结束的时间
+}
+
+func NewSerialClient(config SerialConfig) SerialClient {
+	return SerialClient{
+		Config:      config,
+		Timeout:     serialTimeout,
+		IdleTimeout: serialIdleTimeout,
+		FrameDelay:  RTUFrameDelay(config.withDefaults().BaudRate),
+	}
+}
+
+// Send 发送数据到串口，并获取响应数据，dataReader 为 nil 时只发送不读取
+func (t *SerialClient) Send(requestData []byte, dataReader func(r io.Reader) error) (err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err = t.connect(); err != nil {
+		return
+	}
+	t.setCloseTimer()
+	waitFrameDelay(t.lastFrame, t.FrameDelay)
+	t.lastActivity = time.Now()
+	// 设置读写超时时间
+	var timeout time.Time
+	if t.Timeout > 0 {
+		timeout = t.lastActivity.Add(t.Timeout)
+	}
+	if err = t.port.SetDeadline(timeout); err != nil {
+		return
+	}
+	// 总线静默期间收到的数据（如因字符间隔超时而丢弃的响应的剩余部分）不属于本次请求的响应
+	if err = t.port.Flush(); err != nil {
+		return
+	}
+	// 发送数据，写入返回时数据可能仍在发送缓冲区中，按波特率估算请求在总线上结束的时间
+	if _, err = t.port.Write(requestData); err != nil {
+		return
+	}
+	t.lastFrame = t.lastActivity.Add(t.Config.transmitTime(len(requestData)))
+	if dataReader == nil {
+		return
+	}
+	err = dataReader(t.port)
+	t.lastFrame = time.Now()
+	if err != nil {
+		// 丢弃不完整或迟到的响应，避免影响下一次请求
+		_ = t.port.Flush()
+		return
+	}
+	return
+}
+
+// Connect 封装给外部使用
+func (t *SerialClient) Connect() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.connect()
+}
+
+// 如果串口未打开，打开串口
+func (t *SerialClient) connect() error {
+	if t.port == nil {
+		port, err := OpenSerialPort(t.Config)
+		if err != nil {
+			return err
+		}
+		t.port = port
+	}
+	return nil
+}
+
+// Close 封装给外部使用
+func (t *SerialClient) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.close()
+}
+
+// 关闭串口
+func (t *SerialClient) close() (err error) {
+	if t.port != nil {
+		err = t.port.Close()
+		t.port = nil
+	}
+	return
+}
+
+// 启动闲置串口检测
+func (t *SerialClient) setCloseTimer() {
+	if t.IdleTimeout <= 0 {
+		return
+	}
+	if t.closeTimer == nil {
+		t.closeTimer = time.AfterFunc(t.IdleTimeout, t.closeIdle)
+	} else {
+		t.closeTimer.Reset(t.IdleTimeout)
+	}
+}
+
+// closeIdle 如果闲置时间超过 IdleTimeout ，则关闭串口
+func (t *SerialClient) closeIdle() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.IdleTimeout <= 0 {
+		return
+	}
+	idle := time.Now().Sub(t.lastActivity)
+	if idle >= t.IdleTimeout {
+		slog.Info("serial client: closing port due to idle timeout", "idle", idle)
+		_ = t.close()
+	}
+}
@@ -0,0 +1,98 @@
+package common
+
+import (
+	"os"
+	"time"
+)
+
+const (
+	ParityNone = "N"
+	ParityEven = "E"
+	ParityOdd  = "O"
+)
+
+// SerialConfig 串口配置，零值字段使用 Modbus RTU 的默认值：19200 波特率，8 数据位，偶校验，1 停止位
+type SerialConfig struct {
+	// Address 串口设备路径，如 /dev/ttyUSB0
+	Address  string
+	BaudRate int
+	// DataBits 数据位，5、6、7 或 8
+	DataBits int
+	// StopBits 停止位，1 或 2
+	StopBits int
+	// Parity 校验方式，ParityNone、ParityEven 或 ParityOdd
+	Parity string
+}
+
+// withDefaults 返回填充了默认值的配置
+func (c SerialConfig) withDefaults() SerialConfig {
+	if c.BaudRate == 0 {
+		c.BaudRate = 19200
+	}
+	if c.DataBits == 0 {
+		c.DataBits = 8
+	}
+	if c.StopBits == 0 {
+		c.StopBits = 1
+	}
+	if c.Parity == "" {
+		c.Parity = ParityEven
+	}
+	return c
+}
+
+// RTUCharTimeout 按配置的波特率（零值时为默认的 19200）返回读取 RTU 帧时使用的字符间超时，见 SerialRTUCharTimeout
+func (c SerialConfig) RTUCharTimeout() time.Duration {
+	return SerialRTUCharTimeout(c.withDefaults().BaudRate)
+}
+
+// transmitTime 返回按配置发送 n 个字符所需的时间，每个字符包括起始位、数据位、校验位和停止位
+func (c SerialConfig) transmitTime(n int) time.Duration {
+	c = c.withDefaults()
+	bits := 1 + c.DataBits + c.StopBits
+	if c.Parity != ParityNone {
+		bits++
+	}
+	return time.Duration(n*bits) * time.Second / time.Duration(c.BaudRate)
+}
+
+// SerialPort 已打开的串口，读写支持超时时间
+type SerialPort struct {
+	file *os.File
+}
+
+// OpenSerialPort 打开串口并按配置设置为原始模式
+func OpenSerialPort(config SerialConfig) (*SerialPort, error) {
+	file, err := openSerialPort(config.withDefaults())
+	if err != nil {
+		return nil, err
+	}
+	return &SerialPort{file: file}, nil
+}
+
+func (p *SerialPort) Read(b []byte) (int, error) {
+	return p.file.Read(b)
+}
+
+func (p *SerialPort) Write(b []byte) (int, error) {
+	return p.file.Write(b)
+}
+
+func (p *SerialPort) Close() error {
+	return p.file.Close()
+}
+
+// SetDeadline 设置读写超时时间，零值表示不超时
+func (p *SerialPort) SetDeadline(t time.Time) error {
+	return p.file.SetDeadline(t)
+}
+
+// SetReadDeadline 设置读取超时时间，零值表示不超时
+func (p *SerialPort) SetReadDeadline(t time.Time) error {
+	return p.file.SetReadDeadline(t)
+}
+
+// Flush 丢弃输入缓冲区中尚未读取的数据
+func (p *SerialPort) Flush() error {
+	return flushSerialPort(p.file)
+}
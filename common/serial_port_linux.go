@@ -0,0 +1,135 @@
+//go:build linux
+
+package common
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+var serialBaudRates = map[int]uint32{
+	1200:   unix.B1200,
+	2400:   unix.B2400,
+	4800:   unix.B4800,
+	9600:   unix.B9600,
+	19200:  unix.B19200,
+	38400:  unix.B38400,
+	57600:  unix.B57600,
+	115200: unix.B115200,
+	230400: unix.B230400,
+	460800: unix.B460800,
+	921600: unix.B921600,
+}
+
+var serialDataBits = map[int]uint32{
+	5: unix.CS5,
+	6: unix.CS6,
+	7: unix.CS7,
+	8: unix.CS8,
+}
+
+// openSerialPort 以非阻塞方式打开串口，使 os.File 的超时时间生效
+func openSerialPort(config SerialConfig) (*os.File, error) {
+	fd, err := unix.Open(config.Address, unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("modbus: open serial port '%v': %w", config.Address, err)
+	}
+	if err = setSerialTermios(fd, config); err != nil {
+		_ = unix.Close(fd)
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), config.Address), nil
+}
+
+// setSerialTermios 设置为原始模式，并设置波特率、数据位、校验位和停止位
+func setSerialTermios(fd int, config SerialConfig) error {
+	rate, ok := serialBaudRates[config.BaudRate]
+	if !ok {
+		return fmt.Errorf("modbus: baud rate '%v' is not supported", config.BaudRate)
+	}
+	size, ok := serialDataBits[config.DataBits]
+	if !ok {
+		return fmt.Errorf("modbus: data bits '%v' is not supported", config.DataBits)
+	}
+	termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return fmt.Errorf("modbus: get serial port attributes: %w", err)
+	}
+	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON | unix.IXOFF | unix.IXANY
+	termios.Oflag &^= unix.OPOST
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	termios.Cflag &^= unix.CSIZE | unix.PARENB | unix.PARODD | unix.CSTOPB | unix.CBAUD | unix.CRTSCTS
+	termios.Cflag |= size | rate | unix.CREAD | unix.CLOCAL
+	termios.Ispeed = rate
+	termios.Ospeed = rate
+	switch config.Parity {
+	case ParityNone:
+	case ParityEven:
+		termios.Cflag |= unix.PARENB
+		termios.Iflag |= unix.INPCK
+	case ParityOdd:
+		termios.Cflag |= unix.PARENB | unix.PARODD
+		termios.Iflag |= unix.INPCK
+	default:
+		return fmt.Errorf("modbus: parity '%v' is not supported", config.Parity)
+	}
+	switch config.StopBits {
+	case 1:
+	case 2:
+		termios.Cflag |= unix.CSTOPB
+	default:
+		return fmt.Errorf("modbus: stop bits '%v' is not supported", config.StopBits)
+	}
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	if err = unix.IoctlSetTermios(fd, unix.TCSETS, termios); err != nil {
+		return fmt.Errorf("modbus: set serial port attributes: %w", err)
+	}
+	return nil
+}
+
+func flushSerialPort(file *os.File) error {
+	conn, err := file.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var flushErr error
+	err = conn.Control(func(fd uintptr) {
+		flushErr = unix.IoctlSetInt(int(fd), unix.TCFLSH, unix.TCIFLUSH)
+	})
+	if err != nil {
+		return err
+	}
+	return flushErr
+}
+
+// OpenPseudoTerminal 创建一对伪终端，返回主设备端和从设备端的路径，从设备端可以作为串口打开，
+// 用于在没有串口硬件时测试串口主站和从站
+func OpenPseudoTerminal(config SerialConfig) (port *SerialPort, name string, err error) {
+	fd, err := unix.Open("/dev/ptmx", unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		err = fmt.Errorf("modbus: open pseudo terminal: %w", err)
+		return
+	}
+	if err = unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		_ = unix.Close(fd)
+		err = fmt.Errorf("modbus: unlock pseudo terminal: %w", err)
+		return
+	}
+	number, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		_ = unix.Close(fd)
+		err = fmt.Errorf("modbus: get pseudo terminal number: %w", err)
+		return
+	}
+	// 主设备端的终端属性作用于从设备端
+	if err = setSerialTermios(fd, config.withDefaults()); err != nil {
+		_ = unix.Close(fd)
+		return
+	}
+	name = fmt.Sprintf("/dev/pts/%d", number)
+	port = &SerialPort{file: os.NewFile(uintptr(fd), "/dev/ptmx")}
+	return
+}
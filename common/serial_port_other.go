@@ -0,0 +1,23 @@
+//go:build !linux
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+func openSerialPort(config SerialConfig) (*os.File, error) {
+	return nil, fmt.Errorf("modbus: serial port is not supported on '%v'", runtime.GOOS)
+}
+
+func flushSerialPort(file *os.File) error {
+	return fmt.Errorf("modbus: serial port is not supported on '%v'", runtime.GOOS)
+}
+
+// OpenPseudoTerminal 创建一对伪终端，仅支持 Linux
+func OpenPseudoTerminal(config SerialConfig) (port *SerialPort, name string, err error) {
+	err = fmt.Errorf("modbus: pseudo terminal is not supported on '%v'", runtime.GOOS)
+	return
+}
@@ -0,0 +1,178 @@
+package common
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// SerialServer 串口从站服务，在同一条 RS-485 总线上承载多个 RTU 或 ASCII 从站设备
+type SerialServer struct {
+	Config SerialConfig
+	// FrameTimeout RTU 帧收到数据后超过该时间没有新数据则视为一帧结束，
+	// NewSerialServer 按波特率设置为 3.5 字符时间加上驱动交付数据的延迟，见 SerialRTUFrameTimeout
+	FrameTimeout time.Duration
+	// CharTimeout ASCII 帧中两个字符的最大间隔，超过时丢弃未完成的帧
+	CharTimeout time.Duration
+
+	frameType FrameType
+	devices   []*ModbusDevice
+	mu        sync.Mutex
+	port      *SerialPort
+	closed    bool
+}
+
+func NewSerialServer(config SerialConfig) *SerialServer {
+	return &SerialServer{
+		Config:       config,
+		FrameTimeout: SerialRTUFrameTimeout(config.withDefaults().BaudRate),
+		CharTimeout:  DefaultASCIICharTimeout,
+		devices:      make([]*ModbusDevice, 0),
+	}
+}
+
+// Enroll 添加从站设备，串口上支持 RTU 或 ASCII 帧，同一总线上的设备必须使用相同的帧格式
+func (s *SerialServer) Enroll(device *ModbusDevice) {
+	if device.FrameType != FrameTypeRTU && device.FrameType != FrameTypeASCII {
+		panic("serial server only supports RTU and ASCII devices")
+	}
+	if len(s.devices) > 0 && s.frameType != device.FrameType {
+		panic("serial server devices must use the same frame type")
+	}
+	s.frameType = device.FrameType
+	for _, dev := range s.devices {
+		if dev.SlaveId == device.SlaveId {
+			panic("device already exists")
+		}
+	}
+	s.devices = append(s.devices, device)
+}
+
+// ListenAndServe 打开 Config 指定的串口并处理请求，直到 Close 被调用
+func (s *SerialServer) ListenAndServe() error {
+	port, err := OpenSerialPort(s.Config)
+	if err != nil {
+		return err
+	}
+	return s.Serve(port)
+}
+
+// Serve 在已打开的串口上处理请求，直到 Close 被调用，返回时关闭串口
+func (s *SerialServer) Serve(port *SerialPort) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = port.Close()
+		return os.ErrClosed
+	}
+	s.port = port
+	s.mu.Unlock()
+	defer func() { _ = port.Close() }()
+
+	if s.frameType == FrameTypeASCII {
+		return s.serveASCII(port)
+	}
+	buf := make([]byte, rtuMaxSize)
+	for {
+		length, err := s.readFrame(port, buf)
+		if err != nil {
+			if s.isClosed() {
+				return nil
+			}
+			return err
+		}
+		if length > rtuMaxSize {
+			slog.Warn("serial request too long", "length", length)
+			continue
+		}
+		if length < rtuMinSize {
+			slog.Warn("invalid request data", "frame", FrameTypeRTU, "length", length)
+			continue
+		}
+		s.reply(port, dispatchRequest(s.devices, FrameTypeRTU, buf[0], buf[:length]))
+	}
+}
+
+// serveASCII 按 ':' 和设备的结束符读取 ASCII 帧并处理
+func (s *SerialServer) serveASCII(port *SerialPort) error {
+	for {
+		// 等待帧的第一个字符
+		if err := port.SetReadDeadline(time.Time{}); err != nil {
+			return err
+		}
+		frame, err := readASCIIFrame(port, s.CharTimeout, asciiDelimiters(s.devices))
+		if err != nil {
+			if s.isClosed() {
+				return nil
+			}
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				slog.Warn("discard incomplete ascii frame", "error", err)
+				continue
+			}
+			return err
+		}
+		slaveId, ok := asciiSlaveId(frame)
+		if !ok {
+			slog.Warn("invalid request data", "frame", FrameTypeASCII, "length", len(frame))
+			continue
+		}
+		s.reply(port, dispatchRequest(s.devices, FrameTypeASCII, slaveId, frame))
+	}
+}
+
+// reply 写入响应，广播或仅监听模式等没有响应时不写入
+func (s *SerialServer) reply(port *SerialPort, responseData []byte) {
+	if len(responseData) == 0 {
+		return
+	}
+	if _, err := port.Write(responseData); err != nil {
+		slog.Warn("write response data error", "error", err)
+	}
+}
+
+// readFrame 读取一帧数据到 buf，超过 FrameTimeout 没有新数据时一帧结束，
+// 超出 buf 的数据被丢弃，返回的长度大于 len(buf) 表示帧过长
+func (s *SerialServer) readFrame(port *SerialPort, buf []byte) (length int, err error) {
+	// 等待帧的第一个字节
+	if err = port.SetReadDeadline(time.Time{}); err != nil {
+		return
+	}
+	discard := make([]byte, 64)
+	for {
+		var n int
+		if length < len(buf) {
+			n, err = port.Read(buf[length:])
+		} else {
+			n, err = port.Read(discard)
+		}
+		length += n
+		if err != nil {
+			if length > 0 && errors.Is(err, os.ErrDeadlineExceeded) {
+				return length, nil
+			}
+			return
+		}
+		if err = port.SetReadDeadline(time.Now().Add(s.FrameTimeout)); err != nil {
+			return
+		}
+	}
+}
+
+// Close 停止服务并关闭串口
+func (s *SerialServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.port != nil {
+		return s.port.Close()
+	}
+	return nil
+}
+
+func (s *SerialServer) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
@@ -0,0 +1,50 @@
+package common
+
+import "fmt"
+
+// Report Server ID (功能码 0x11) 中运行指示的取值
+const (
+	RunIndicatorOff byte = 0x00
+	RunIndicatorOn  byte = 0xFF
+)
+
+// ServerID Report Server ID (功能码 0x11) 的响应。
+// 服务器 ID 的长度由设备自行定义，Data 保存除字节数外的原始数据，可使用 DecodeServerID 按其它长度重新解析
+type ServerID struct {
+	ID             []byte // 服务器 ID
+	RunIndicator   bool   // 运行指示，0xFF 为运行
+	AdditionalData []byte // 附加数据
+	Data           []byte // 原始数据
+}
+
+// DecodeServerID 按照 idLength 字节的服务器 ID 解析 Report Server ID 的响应数据（不含字节数）
+func DecodeServerID(data []byte, idLength int) (serverID *ServerID, err error) {
+	if idLength < 0 || len(data) < idLength+1 {
+		err = fmt.Errorf("modbus: server id data size '%v' is less than expected '%v'", len(data), idLength+1)
+		return
+	}
+	indicator := data[idLength]
+	if indicator != RunIndicatorOff && indicator != RunIndicatorOn {
+		err = fmt.Errorf("modbus: run indicator '%v' must be either '%v' or '%v'", indicator, RunIndicatorOff, RunIndicatorOn)
+		return
+	}
+	serverID = &ServerID{
+		ID:             append([]byte(nil), data[:idLength]...),
+		RunIndicator:   indicator == RunIndicatorOn,
+		AdditionalData: append([]byte(nil), data[idLength+1:]...),
+		Data:           append([]byte(nil), data...),
+	}
+	return
+}
+
+// Bytes 将服务器 ID、运行指示和附加数据编码为响应数据（不含字节数）
+func (s *ServerID) Bytes() []byte {
+	data := make([]byte, 0, len(s.ID)+1+len(s.AdditionalData))
+	data = append(data, s.ID...)
+	if s.RunIndicator {
+		data = append(data, RunIndicatorOn)
+	} else {
+		data = append(data, RunIndicatorOff)
+	}
+	return append(data, s.AdditionalData...)
+}
@@ -0,0 +1,385 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StructTagName 结构体字段映射使用的标签名
+const StructTagName = "modbus"
+
+// StructField 带 modbus 标签的结构体字段，标签格式为 `modbus:"区域,地址[,类型][,选项...]"`，如 `modbus:"hr,100,float32,cdab"`、`modbus:"coil,5"`：
+//
+//	区域：coil、di、hr、ir 分别对应线圈、离散输入、保持寄存器、输入寄存器
+//	类型：int16、uint16、int32、uint32、float32、int64、uint64、float64、string，省略时由字段类型推断，线圈和离散输入字段只能为 bool
+//	选项：字节序 abcd（默认）、cdab、badc、dcba；字符串占用的寄存器数量 len=N；字符串字内低字节在前 swap
+//
+// 标签为 "-" 或没有标签的字段被忽略
+type StructField struct {
+	Name         string    // 字段名
+	Index        int       // 字段在结构体中的下标
+	FunctionCode byte      // 区域对应的读取功能码 0x01-0x04
+	Address      uint16    // 起始地址
+	Quantity     uint16    // 占用的点数
+	Type         string    // 编码类型，线圈和离散输入为 bool
+	Order        ByteOrder // 数值的字节序
+	SwapBytes    bool      // 字符串字内低字节在前
+}
+
+// IsBit 是否线圈或离散输入字段
+func (f *StructField) IsBit() bool {
+	return f.FunctionCode == FuncCodeReadCoils || f.FunctionCode == FuncCodeReadDiscreteInputs
+}
+
+// IsWritable 是否线圈或保持寄存器字段
+func (f *StructField) IsWritable() bool {
+	return f.FunctionCode == FuncCodeReadCoils || f.FunctionCode == FuncCodeReadHoldingRegisters
+}
+
+var (
+	structAreas = map[string]byte{
+		"coil": FuncCodeReadCoils,
+		"di":   FuncCodeReadDiscreteInputs,
+		"hr":   FuncCodeReadHoldingRegisters,
+		"ir":   FuncCodeReadInputRegisters,
+	}
+	structOrders = map[string]ByteOrder{
+		"abcd": ByteOrderABCD,
+		"cdab": ByteOrderCDAB,
+		"badc": ByteOrderBADC,
+		"dcba": ByteOrderDCBA,
+	}
+	structTypeSizes = map[string]int{
+		"int16":   1,
+		"uint16":  1,
+		"int32":   2,
+		"uint32":  2,
+		"float32": 2,
+		"int64":   4,
+		"uint64":  4,
+		"float64": 4,
+	}
+	// structFields 按结构体类型缓存的解析结果
+	structFields sync.Map
+)
+
+// StructValue 返回结构体指针 v 指向的结构体
+func StructValue(v any) (reflect.Value, error) {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("modbus: value of type '%T' is not a non-nil struct pointer", v)
+	}
+	return value.Elem(), nil
+}
+
+// ParseStruct 解析结构体类型中带 modbus 标签的字段，按区域和地址排序，同一区域内的字段地址不能重叠。
+// 解析结果按类型缓存，调用方不应修改
+func ParseStruct(t reflect.Type) ([]*StructField, error) {
+	if cached, ok := structFields.Load(t); ok {
+		return cached.([]*StructField), nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("modbus: type '%v' is not a struct", t)
+	}
+	var fields []*StructField
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		tag, ok := structField.Tag.Lookup(StructTagName)
+		if !ok || tag == "-" {
+			continue
+		}
+		if !structField.IsExported() {
+			return nil, fmt.Errorf("modbus: field '%v' with tag '%v' is not exported", structField.Name, tag)
+		}
+		field, err := parseStructField(structField, tag)
+		if err != nil {
+			return nil, err
+		}
+		field.Index = i
+		fields = append(fields, field)
+	}
+	sort.SliceStable(fields, func(a, b int) bool {
+		if fields[a].FunctionCode != fields[b].FunctionCode {
+			return fields[a].FunctionCode < fields[b].FunctionCode
+		}
+		return fields[a].Address < fields[b].Address
+	})
+	for i := 1; i < len(fields); i++ {
+		prev, field := fields[i-1], fields[i]
+		if prev.FunctionCode == field.FunctionCode && int(prev.Address)+int(prev.Quantity) > int(field.Address) {
+			return nil, fmt.Errorf("modbus: field '%v' overlaps field '%v'", field.Name, prev.Name)
+		}
+	}
+	cached, _ := structFields.LoadOrStore(t, fields)
+	return cached.([]*StructField), nil
+}
+
+// parseStructField 解析单个字段的标签
+func parseStructField(structField reflect.StructField, tag string) (field *StructField, err error) {
+	parts := strings.Split(tag, ",")
+	if len(parts) < 2 {
+		err = fmt.Errorf("modbus: tag '%v' of field '%v' must contain area and address", tag, structField.Name)
+		return
+	}
+	field = &StructField{Name: structField.Name}
+	functionCode, ok := structAreas[strings.TrimSpace(parts[0])]
+	if !ok {
+		err = fmt.Errorf("modbus: area '%v' of field '%v' is not one of coil, di, hr, ir", parts[0], structField.Name)
+		return
+	}
+	field.FunctionCode = functionCode
+	address, err := strconv.ParseUint(strings.TrimSpace(parts[1]), 0, 16)
+	if err != nil {
+		err = fmt.Errorf("modbus: address '%v' of field '%v' is invalid: %w", parts[1], structField.Name, err)
+		return
+	}
+	field.Address = uint16(address)
+
+	length := 0
+	for _, part := range parts[2:] {
+		part = strings.TrimSpace(part)
+		if order, ok := structOrders[part]; ok {
+			field.Order = order
+			continue
+		}
+		if _, ok := structTypeSizes[part]; ok || part == "string" {
+			field.Type = part
+			continue
+		}
+		switch {
+		case part == "swap":
+			field.SwapBytes = true
+		case strings.HasPrefix(part, "len="):
+			length, err = strconv.Atoi(part[len("len="):])
+			if err != nil || length < 1 {
+				err = fmt.Errorf("modbus: option '%v' of field '%v' is invalid", part, structField.Name)
+				return
+			}
+		case part == "":
+		default:
+			err = fmt.Errorf("modbus: option '%v' of field '%v' is unknown", part, structField.Name)
+			return
+		}
+	}
+
+	kind := structField.Type.Kind()
+	if field.IsBit() {
+		if kind != reflect.Bool || field.Type != "" {
+			err = fmt.Errorf("modbus: field '%v' in area '%v' must be bool", structField.Name, parts[0])
+			return
+		}
+		field.Type = "bool"
+		field.Quantity = 1
+		return
+	}
+	if field.Type == "" {
+		field.Type = defaultStructType(kind)
+	}
+	var quantity int
+	switch {
+	case field.Type == "string":
+		if kind != reflect.String {
+			err = fmt.Errorf("modbus: field '%v' of type string must be string", structField.Name)
+			return
+		}
+		if length == 0 {
+			err = fmt.Errorf("modbus: string field '%v' requires option len=N", structField.Name)
+			return
+		}
+		quantity = length
+	case field.Type == "":
+		err = fmt.Errorf("modbus: type '%v' of field '%v' is not supported", structField.Type, structField.Name)
+		return
+	case isFloatKind(kind) || isIntKind(kind) || isUintKind(kind):
+		if strings.HasPrefix(field.Type, "float") && !isFloatKind(kind) {
+			err = fmt.Errorf("modbus: field '%v' of type '%v' must be float32 or float64", structField.Name, field.Type)
+			return
+		}
+		quantity = structTypeSizes[field.Type]
+	default:
+		err = fmt.Errorf("modbus: type '%v' of field '%v' does not match '%v'", structField.Type, structField.Name, field.Type)
+		return
+	}
+	if int(field.Address)+quantity > 0x10000 {
+		err = fmt.Errorf("modbus: field '%v' exceeds address '%v'", structField.Name, 0xFFFF)
+		return
+	}
+	field.Quantity = uint16(quantity)
+	return
+}
+
+// defaultStructType 由字段类型推断编码类型，无法推断时返回空
+func defaultStructType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int8, reflect.Int16:
+		return "int16"
+	case reflect.Uint8, reflect.Uint16:
+		return "uint16"
+	case reflect.Int32:
+		return "int32"
+	case reflect.Uint32:
+		return "uint32"
+	case reflect.Int, reflect.Int64:
+		return "int64"
+	case reflect.Uint, reflect.Uint64:
+		return "uint64"
+	case reflect.Float32:
+		return "float32"
+	case reflect.Float64:
+		return "float64"
+	case reflect.String:
+		return "string"
+	}
+	return ""
+}
+
+// Encode 将结构体 v 中该字段的值编码为点值，线圈和离散输入为 0 或 1
+func (f *StructField) Encode(v reflect.Value) ([]uint16, error) {
+	value := v.Field(f.Index)
+	switch f.Type {
+	case "bool":
+		if value.Bool() {
+			return []uint16{1}, nil
+		}
+		return []uint16{0}, nil
+	case "string":
+		registers, err := EncodeString(value.String(), int(f.Quantity), f.SwapBytes)
+		if err != nil {
+			return nil, fmt.Errorf("modbus: field '%v': %w", f.Name, err)
+		}
+		return RegisterValues(registers), nil
+	case "int16":
+		return encodeStructNumber[int16](value, f.Order), nil
+	case "uint16":
+		return encodeStructNumber[uint16](value, f.Order), nil
+	case "int32":
+		return encodeStructNumber[int32](value, f.Order), nil
+	case "uint32":
+		return encodeStructNumber[uint32](value, f.Order), nil
+	case "float32":
+		return encodeStructNumber[float32](value, f.Order), nil
+	case "int64":
+		return encodeStructNumber[int64](value, f.Order), nil
+	case "uint64":
+		return encodeStructNumber[uint64](value, f.Order), nil
+	case "float64":
+		return encodeStructNumber[float64](value, f.Order), nil
+	}
+	return nil, fmt.Errorf("modbus: type '%v' of field '%v' is not supported", f.Type, f.Name)
+}
+
+// Decode 将点值解码到结构体 v 的该字段，words 的数量必须与 Quantity 一致
+func (f *StructField) Decode(v reflect.Value, words []uint16) error {
+	if len(words) != int(f.Quantity) {
+		return fmt.Errorf("modbus: field '%v' requires '%v' points, got '%v'", f.Name, f.Quantity, len(words))
+	}
+	value := v.Field(f.Index)
+	switch f.Type {
+	case "bool":
+		value.SetBool(words[0] != 0)
+		return nil
+	case "string":
+		value.SetString(DecodeString(NewRegistersFromUInt16s(words), f.SwapBytes))
+		return nil
+	case "int16":
+		return decodeStructNumber[int16](f, value, words)
+	case "uint16":
+		return decodeStructNumber[uint16](f, value, words)
+	case "int32":
+		return decodeStructNumber[int32](f, value, words)
+	case "uint32":
+		return decodeStructNumber[uint32](f, value, words)
+	case "float32":
+		return decodeStructNumber[float32](f, value, words)
+	case "int64":
+		return decodeStructNumber[int64](f, value, words)
+	case "uint64":
+		return decodeStructNumber[uint64](f, value, words)
+	case "float64":
+		return decodeStructNumber[float64](f, value, words)
+	}
+	return fmt.Errorf("modbus: type '%v' of field '%v' is not supported", f.Type, f.Name)
+}
+
+// encodeStructNumber 将整数或浮点字段转换为 T 后编码
+func encodeStructNumber[T Number](value reflect.Value, order ByteOrder) []uint16 {
+	var number T
+	switch {
+	case isIntKind(value.Kind()):
+		number = T(value.Int())
+	case isUintKind(value.Kind()):
+		number = T(value.Uint())
+	case isFloatKind(value.Kind()):
+		number = T(value.Float())
+	}
+	return EncodeWords([]T{number}, order)
+}
+
+// decodeStructNumber 按 T 解码后转换为字段类型，超出字段类型范围时返回错误
+func decodeStructNumber[T Number](f *StructField, value reflect.Value, words []uint16) error {
+	numbers, err := DecodeWords[T](words, f.Order)
+	if err != nil {
+		return err
+	}
+	number := numbers[0]
+	overflow := false
+	switch {
+	case isIntKind(value.Kind()):
+		n, ok := numberToInt64(number)
+		if overflow = !ok || value.OverflowInt(n); !overflow {
+			value.SetInt(n)
+		}
+	case isUintKind(value.Kind()):
+		n, ok := numberToUint64(number)
+		if overflow = !ok || value.OverflowUint(n); !overflow {
+			value.SetUint(n)
+		}
+	case isFloatKind(value.Kind()):
+		if overflow = value.OverflowFloat(float64(number)); !overflow {
+			value.SetFloat(float64(number))
+		}
+	}
+	if overflow {
+		return fmt.Errorf("modbus: value '%v' overflows field '%v' of type '%v'", number, f.Name, value.Type())
+	}
+	return nil
+}
+
+// numberToInt64 在 T 的范围内检查后转换为 int64，超出 int64 范围或浮点数不是整数时 ok 为 false
+func numberToInt64[T Number](number T) (n int64, ok bool) {
+	switch v := any(number).(type) {
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	case float32, float64:
+		f := float64(number)
+		return int64(f), f == math.Trunc(f) && f >= math.MinInt64 && f < 1<<63
+	}
+	return int64(number), true
+}
+
+// numberToUint64 在 T 的范围内检查后转换为 uint64，为负数或浮点数不是整数时 ok 为 false
+func numberToUint64[T Number](number T) (n uint64, ok bool) {
+	switch any(number).(type) {
+	case float32, float64:
+		f := float64(number)
+		return uint64(f), f == math.Trunc(f) && f >= 0 && f < 1<<64
+	}
+	return uint64(number), number >= 0
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func isUintKind(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uint64
+}
+
+func isFloatKind(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
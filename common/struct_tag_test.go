@@ -0,0 +1,190 @@
+package common
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseStruct(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		want    []StructField
+		wantErr bool
+	}{
+		{name: "inferred types sorted by area and address", value: struct {
+			Speed   float32 `modbus:"hr,10"`
+			Running bool    `modbus:"coil,3"`
+			Count   uint64  `modbus:"ir,0,dcba"`
+			Skipped int     `modbus:"-"`
+			Level   int8    `modbus:"hr,0"`
+		}{}, want: []StructField{
+			{Name: "Running", Index: 1, FunctionCode: FuncCodeReadCoils, Address: 3, Quantity: 1, Type: "bool"},
+			{Name: "Level", Index: 4, FunctionCode: FuncCodeReadHoldingRegisters, Address: 0, Quantity: 1, Type: "int16"},
+			{Name: "Speed", Index: 0, FunctionCode: FuncCodeReadHoldingRegisters, Address: 10, Quantity: 2, Type: "float32"},
+			{Name: "Count", Index: 2, FunctionCode: FuncCodeReadInputRegisters, Address: 0, Quantity: 4, Type: "uint64", Order: ByteOrderDCBA},
+		}},
+		{name: "explicit type and string options", value: struct {
+			Total int64  `modbus:"hr,0x10,int32,cdab"`
+			Name  string `modbus:"hr, 20, string, len=5, swap"`
+		}{}, want: []StructField{
+			{Name: "Total", Index: 0, FunctionCode: FuncCodeReadHoldingRegisters, Address: 16, Quantity: 2, Type: "int32", Order: ByteOrderCDAB},
+			{Name: "Name", Index: 1, FunctionCode: FuncCodeReadHoldingRegisters, Address: 20, Quantity: 5, Type: "string", SwapBytes: true},
+		}},
+		{name: "adjacent fields", value: struct {
+			A uint32 `modbus:"hr,0"`
+			B uint16 `modbus:"hr,2"`
+		}{}, want: []StructField{
+			{Name: "A", Index: 0, FunctionCode: FuncCodeReadHoldingRegisters, Address: 0, Quantity: 2, Type: "uint32"},
+			{Name: "B", Index: 1, FunctionCode: FuncCodeReadHoldingRegisters, Address: 2, Quantity: 1, Type: "uint16"},
+		}},
+		{name: "same address in different areas", value: struct {
+			A uint16 `modbus:"hr,0"`
+			B uint16 `modbus:"ir,0"`
+		}{}, want: []StructField{
+			{Name: "A", Index: 0, FunctionCode: FuncCodeReadHoldingRegisters, Address: 0, Quantity: 1, Type: "uint16"},
+			{Name: "B", Index: 1, FunctionCode: FuncCodeReadInputRegisters, Address: 0, Quantity: 1, Type: "uint16"},
+		}},
+		{name: "overlap", value: struct {
+			A float32 `modbus:"hr,0"`
+			B uint16  `modbus:"hr,1"`
+		}{}, wantErr: true},
+		{name: "string overlap", value: struct {
+			A string `modbus:"hr,0,len=3"`
+			B uint16 `modbus:"hr,2"`
+		}{}, wantErr: true},
+		{name: "string without len", value: struct {
+			A string `modbus:"hr,0"`
+		}{}, wantErr: true},
+		{name: "len zero", value: struct {
+			A string `modbus:"hr,0,len=0"`
+		}{}, wantErr: true},
+		{name: "len not a number", value: struct {
+			A string `modbus:"hr,0,len=x"`
+		}{}, wantErr: true},
+		{name: "unknown option", value: struct {
+			A uint16 `modbus:"hr,0,big"`
+		}{}, wantErr: true},
+		{name: "unknown area", value: struct {
+			A uint16 `modbus:"reg,0"`
+		}{}, wantErr: true},
+		{name: "missing address", value: struct {
+			A uint16 `modbus:"hr"`
+		}{}, wantErr: true},
+		{name: "address out of range", value: struct {
+			A uint16 `modbus:"hr,65536"`
+		}{}, wantErr: true},
+		{name: "field past last address", value: struct {
+			A uint32 `modbus:"hr,65535"`
+		}{}, wantErr: true},
+		{name: "coil not bool", value: struct {
+			A uint16 `modbus:"coil,0"`
+		}{}, wantErr: true},
+		{name: "discrete input with type", value: struct {
+			A bool `modbus:"di,0,uint16"`
+		}{}, wantErr: true},
+		{name: "bool register", value: struct {
+			A bool `modbus:"hr,0"`
+		}{}, wantErr: true},
+		{name: "float type on integer field", value: struct {
+			A int32 `modbus:"hr,0,float32"`
+		}{}, wantErr: true},
+		{name: "string type on integer field", value: struct {
+			A int32 `modbus:"hr,0,string,len=2"`
+		}{}, wantErr: true},
+		{name: "unexported", value: struct {
+			a uint16 `modbus:"hr,0"`
+		}{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := ParseStruct(reflect.TypeOf(tt.value))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(fields) != len(tt.want) {
+				t.Fatalf("fields = %v, want %v", len(fields), len(tt.want))
+			}
+			for i, field := range fields {
+				if *field != tt.want[i] {
+					t.Errorf("field %v = %+v, want %+v", i, *field, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if _, err := ParseStruct(reflect.TypeOf(0)); err == nil {
+		t.Fatal("parse int: got nil error")
+	}
+}
+
+// TestStructFieldDecodeOverflow 解码值超出字段类型范围时返回错误且不修改字段
+func TestStructFieldDecodeOverflow(t *testing.T) {
+	var v struct {
+		Int64 int64   `modbus:"hr,0,uint64"`
+		Int8  int8    `modbus:"hr,4,int32"`
+		Uint  uint    `modbus:"hr,6,int64"`
+		Uint8 uint8   `modbus:"hr,10,uint16"`
+		Float float32 `modbus:"hr,11,float64"`
+	}
+	value := reflect.ValueOf(&v).Elem()
+	fields, err := ParseStruct(value.Type())
+	if err != nil {
+		t.Fatal(err)
+	}
+	field := func(name string) *StructField {
+		for _, f := range fields {
+			if f.Name == name {
+				return f
+			}
+		}
+		t.Fatalf("field %v not found", name)
+		return nil
+	}
+	tests := []struct {
+		field   string
+		words   []uint16
+		wantErr bool
+	}{
+		{field: "Int64", words: EncodeWords([]uint64{math.MaxUint64}, ByteOrderABCD), wantErr: true},
+		{field: "Int64", words: EncodeWords([]uint64{1 << 63}, ByteOrderABCD), wantErr: true},
+		{field: "Int64", words: EncodeWords([]uint64{math.MaxInt64}, ByteOrderABCD)},
+		{field: "Int8", words: EncodeWords([]int32{128}, ByteOrderABCD), wantErr: true},
+		{field: "Int8", words: EncodeWords([]int32{-128}, ByteOrderABCD)},
+		{field: "Uint", words: EncodeWords([]int64{-1}, ByteOrderABCD), wantErr: true},
+		{field: "Uint", words: EncodeWords([]int64{math.MaxInt64}, ByteOrderABCD)},
+		{field: "Uint8", words: []uint16{256}, wantErr: true},
+		{field: "Float", words: EncodeWords([]float64{math.MaxFloat64}, ByteOrderABCD), wantErr: true},
+		{field: "Float", words: EncodeWords([]float64{1.5}, ByteOrderABCD)},
+	}
+	for _, tt := range tests {
+		f := field(tt.field)
+		before := value.Field(f.Index).Interface()
+		err := f.Decode(value, tt.words)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%v %04X: error = %v, want error %v", tt.field, tt.words, err, tt.wantErr)
+		}
+		if tt.wantErr && value.Field(f.Index).Interface() != before {
+			t.Fatalf("%v %04X: field = %v, want unchanged %v", tt.field, tt.words, value.Field(f.Index).Interface(), before)
+		}
+	}
+	if v.Int64 != math.MaxInt64 || v.Int8 != -128 || v.Uint != math.MaxInt64 || v.Float != 1.5 {
+		t.Fatalf("decoded = %+v", v)
+	}
+
+	// 浮点数解码到整数字段时必须是范围内的整数
+	for _, number := range []float64{1.5, math.NaN(), math.Inf(1), 1 << 63} {
+		if _, ok := numberToInt64(number); ok {
+			t.Errorf("%v to int64: got ok", number)
+		}
+	}
+	for _, number := range []float32{-1, 0.5, 1 << 64} {
+		if _, ok := numberToUint64(number); ok {
+			t.Errorf("%v to uint64: got ok", number)
+		}
+	}
+	if n, ok := numberToInt64(float32(-3)); !ok || n != -3 {
+		t.Errorf("-3 to int64 = %v, %v", n, ok)
+	}
+}
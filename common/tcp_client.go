@@ -1,6 +1,7 @@
 package common
 
 import (
+	"crypto/tls"
 	"errors"
 	"log/slog"
 	"net"
@@ -17,11 +18,19 @@ type TCPClient struct {
 	Address     string
 	Timeout     time.Duration
 	IdleTimeout time.Duration
+	// TLSConfig 不为 nil 时使用 TLS 连接（Modbus/TCP Security），最低版本为 TLS 1.2，
+	// 双向认证时在 Certificates 中设置客户端证书
+	TLSConfig *tls.Config
+	// FrameDelay 上一个请求结束后到发送下一个请求前的最小间隔，零值表示不等待。
+	// 通过串口服务器透传 RTU 时可设置为 RTUFrameDelay(波特率)，避免连续的请求在串口总线上连成一帧
+	FrameDelay time.Duration
 
 	mu           sync.Mutex
 	conn         net.Conn
 	closeTimer   *time.Timer
 	lastActivity time.Time
+	lastFrame    time.Time // 上一个请求结束的时间
+	stale        bool      // 上一个请求读取响应失败，其迟到的剩余数据可能在下一个请求前到达
 }
 
 func NewTCPClient(address string) TCPClient {
@@ -32,7 +41,7 @@ func NewTCPClient(address string) TCPClient {
 	}
 }
 
-// Send 发送数据到服务器，并获取响应数据
+// Send 发送数据到服务器，并获取响应数据，dataReader 为 nil 时只发送不读取
 func (t *TCPClient) Send(requestData []byte, dataReader func(conn net.Conn) error) (err error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -41,6 +50,11 @@ func (t *TCPClient) Send(requestData []byte, dataReader func(conn net.Conn) erro
 		return
 	}
 	t.setCloseTimer()
+	waitFrameDelay(t.lastFrame, t.FrameDelay)
+	if t.stale {
+		t.drain()
+		t.stale = false
+	}
 	t.lastActivity = time.Now()
 	// 设置读写超时时间
 	var timeout time.Time
@@ -54,9 +68,15 @@ func (t *TCPClient) Send(requestData []byte, dataReader func(conn net.Conn) erro
 	if _, err = t.conn.Write(requestData); err != nil {
 		return
 	}
+	t.lastFrame = time.Now()
+	if dataReader == nil {
+		return
+	}
 	err = dataReader(t.conn)
+	t.lastFrame = time.Now()
 	if err != nil {
 		t.drain()
+		t.stale = true
 		return
 	}
 	return
@@ -73,7 +93,13 @@ func (t *TCPClient) Connect() error {
 func (t *TCPClient) connect() error {
 	if t.conn == nil {
 		dialer := net.Dialer{Timeout: t.Timeout}
-		conn, err := dialer.Dial("tcp", t.Address)
+		var conn net.Conn
+		var err error
+		if t.TLSConfig != nil {
+			conn, err = tls.DialWithDialer(&dialer, "tcp", t.Address, secureTLSConfig(t.TLSConfig))
+		} else {
+			conn, err = dialer.Dial("tcp", t.Address)
+		}
 		if err != nil {
 			return err
 		}
@@ -138,7 +164,7 @@ func (t *TCPClient) closeIdle() {
 	}
 	idle := time.Now().Sub(t.lastActivity)
 	if idle >= t.IdleTimeout {
-		slog.Info("tcp client: closing connection due to idle timeout: %v", idle)
+		slog.Info("tcp client: closing connection due to idle timeout", "idle", idle)
 		_ = t.close()
 	}
 }
@@ -1,24 +1,51 @@
 package common
 
 import (
+	"bytes"
+	"context"
 	"log/slog"
+	"net"
+	"time"
 
 	"github.com/panjf2000/gnet/v2"
 )
 
 type connectionContext struct {
-	SlaveId   uint8
 	FrameType FrameType
+	// pending ASCII 连接中尚未收到 CRLF 的字节数，lastTraffic 为最后一次收到数据的时间
+	pending     int
+	lastTraffic time.Time
 }
 
+// slaveId 返回请求帧中的从站地址
+func (c *connectionContext) slaveId(buf []byte) (uint8, bool) {
+	switch c.FrameType {
+	case FrameTypeMBAP:
+		if len(buf) > mbapHeaderSize {
+			return buf[mbapHeaderSize-1], true
+		}
+	case FrameTypeRTU:
+		if len(buf) >= rtuMinSize {
+			return buf[0], true
+		}
+	case FrameTypeASCII:
+		return asciiSlaveId(buf)
+	}
+	return 0, false
+}
+
+// NetServer 基于 gnet 的从站服务，同时支持 TCP 和 UDP，如 gnet.Rotate(server, []string{"tcp://:502", "udp://:502"})
 type NetServer struct {
 	gnet.BuiltinEventEngine
-	devices []*ModbusDevice
+	// CharTimeout ASCII 帧中两次收到数据的最大间隔，超过时丢弃未完成的帧
+	CharTimeout time.Duration
+	devices     []*ModbusDevice
 }
 
 func NewNetServer() *NetServer {
 	return &NetServer{
-		devices: make([]*ModbusDevice, 0),
+		CharTimeout: DefaultASCIICharTimeout,
+		devices:     make([]*ModbusDevice, 0),
 	}
 }
 
@@ -42,19 +69,33 @@ func (s *NetServer) OnClose(c gnet.Conn, err error) (action gnet.Action) {
 }
 
 func (s *NetServer) OnTraffic(c gnet.Conn) gnet.Action {
-	buf, _ := c.Next(-1)
+	if _, ok := c.LocalAddr().(*net.UDPAddr); ok {
+		return s.handleDatagram(c)
+	}
 	deviceContext := c.Context()
+	if deviceContext == nil {
+		// ASCII 帧可能分多次到达，检测时不取出数据
+		buf, _ := c.Peek(-1)
+		if _, ok := asciiSlaveId(buf); ok && !isBinaryFrame(buf) {
+			deviceContext = &connectionContext{
+				FrameType: FrameTypeASCII,
+			}
+			c.SetContext(deviceContext)
+		}
+	}
+	if ctx, ok := deviceContext.(*connectionContext); ok && ctx.FrameType == FrameTypeASCII {
+		return s.handleASCII(c, ctx)
+	}
+	buf, _ := c.Next(-1)
 	if deviceContext == nil {
 		//自动检测协议类型
-		if mbapFrame, err := NewMBAPFrameFromBytes(buf); err == nil {
+		if _, err := NewMBAPFrameFromBytes(buf); err == nil {
 			deviceContext = &connectionContext{
-				SlaveId:   mbapFrame.UnitId,
 				FrameType: FrameTypeMBAP,
 			}
 		}
-		if rtuFrame, err := NewRTUFrameFromBytes(buf); err == nil {
+		if _, err := NewRTUFrameFromBytes(buf); err == nil {
 			deviceContext = &connectionContext{
-				SlaveId:   rtuFrame.SlaveId,
 				FrameType: FrameTypeRTU,
 			}
 		}
@@ -64,20 +105,133 @@ func (s *NetServer) OnTraffic(c gnet.Conn) gnet.Action {
 	}
 	if deviceContext != nil {
 		ctx := deviceContext.(*connectionContext)
-		for _, device := range s.devices {
-			if device.SlaveId == ctx.SlaveId && device.FrameType == ctx.FrameType {
-				responseData, err := device.Transport.Send(buf)
-				if err != nil {
-					slog.Warn("handle request data error", "error", err)
-					break
-				}
-				_, err = c.Write(responseData)
-				if err != nil {
-					slog.Warn("write response data error", "error", err)
-					break
+		slaveId, ok := ctx.slaveId(buf)
+		if !ok {
+			slog.Warn("invalid request data", "frame", ctx.FrameType, "length", len(buf))
+			return gnet.None
+		}
+		responseData := dispatchRequest(s.devices, ctx.FrameType, slaveId, buf)
+		// 无需响应（如广播或仅监听模式）
+		if len(responseData) == 0 {
+			return gnet.None
+		}
+		if _, err := c.Write(responseData); err != nil {
+			slog.Warn("write response data error", "error", err)
+		}
+	}
+	return gnet.None
+}
+
+// handleASCII 按设备的结束符（默认为 LF）拆分 ASCII 帧，未完成的帧留在连接的缓冲区中等待后续数据
+func (s *NetServer) handleASCII(c gnet.Conn, ctx *connectionContext) gnet.Action {
+	now := time.Now()
+	if ctx.pending > 0 && s.CharTimeout > 0 && now.Sub(ctx.lastTraffic) > s.CharTimeout {
+		slog.Warn("discard incomplete ascii frame", "length", ctx.pending)
+		_, _ = c.Discard(ctx.pending)
+	}
+	ctx.lastTraffic = now
+	ctx.pending = 0
+	delimiters := asciiDelimiters(s.devices)
+	for {
+		buf, _ := c.Peek(-1)
+		end := indexASCIIDelimiter(buf, delimiters)
+		if end < 0 {
+			if len(buf) > asciiMaxSize {
+				slog.Warn("ascii request too long", "length", len(buf))
+				_, _ = c.Discard(len(buf))
+				return gnet.None
+			}
+			ctx.pending = len(buf)
+			return gnet.None
+		}
+		// ':' 之前的数据被丢弃，收到新的 ':' 时重新开始
+		if start := bytes.LastIndexByte(buf[:end], asciiStart); start >= 0 {
+			frame := buf[start : end+1]
+			if slaveId, ok := asciiSlaveId(frame); ok {
+				responseData := dispatchRequest(s.devices, FrameTypeASCII, slaveId, frame)
+				if len(responseData) > 0 {
+					if _, err := c.Write(responseData); err != nil {
+						slog.Warn("write response data error", "error", err)
+					}
 				}
 			}
 		}
+		_, _ = c.Discard(end + 1)
+	}
+}
+
+// handleDatagram 处理 UDP 数据报，每个数据报为一个完整的帧，单独检测帧格式，响应发送到数据报的来源地址
+func (s *NetServer) handleDatagram(c gnet.Conn) gnet.Action {
+	buf, _ := c.Next(-1)
+	ctx := &connectionContext{}
+	if _, err := NewMBAPFrameFromBytes(buf); err == nil {
+		ctx.FrameType = FrameTypeMBAP
+	}
+	if _, err := NewRTUFrameFromBytes(buf); err == nil {
+		ctx.FrameType = FrameTypeRTU
+	}
+	if ctx.FrameType == "" {
+		if _, err := NewASCIIFrameFromBytes(buf); err == nil {
+			ctx.FrameType = FrameTypeASCII
+		}
+	}
+	slaveId, ok := ctx.slaveId(buf)
+	if !ok {
+		slog.Warn("invalid datagram", "remote", c.RemoteAddr(), "length", len(buf))
+		return gnet.None
+	}
+	responseData := dispatchRequest(s.devices, ctx.FrameType, slaveId, buf)
+	if len(responseData) == 0 {
+		return gnet.None
+	}
+	if _, err := c.Write(responseData); err != nil {
+		slog.Warn("write response data error", "remote", c.RemoteAddr(), "error", err)
 	}
 	return gnet.None
 }
+
+// isBinaryFrame 判断数据是否为完整的 MBAP 或 RTU 帧，用于区分以 ':' 开头的二进制帧
+func isBinaryFrame(buf []byte) bool {
+	if _, err := NewMBAPFrameFromBytes(buf); err == nil {
+		return true
+	}
+	_, err := NewRTUFrameFromBytes(buf)
+	return err == nil
+}
+
+// dispatchRequest 将请求交给 frameType 和 slaveId 匹配的设备处理并返回响应，
+// RTU 和 ASCII 帧的广播请求交给所有设备处理，不返回响应；MBAP 帧的单元 ID 0 常用于直接访问服务器，按普通地址处理
+func dispatchRequest(devices []*ModbusDevice, frameType FrameType, slaveId uint8, buf []byte) []byte {
+	return dispatchRequestContext(context.Background(), devices, frameType, slaveId, buf)
+}
+
+// dispatchRequestContext 同 dispatchRequest，设备的通信层实现 ContextTransport 时传入 ctx
+func dispatchRequestContext(ctx context.Context, devices []*ModbusDevice, frameType FrameType, slaveId uint8, buf []byte) []byte {
+	for _, device := range devices {
+		if device.FrameType != frameType {
+			continue
+		}
+		if slaveId == BroadcastSlaveId && frameType != FrameTypeMBAP {
+			if _, err := sendContext(ctx, device.Transport, buf); err != nil {
+				slog.Warn("handle broadcast request error", "error", err)
+			}
+			continue
+		}
+		if device.SlaveId == slaveId {
+			responseData, err := sendContext(ctx, device.Transport, buf)
+			if err != nil {
+				slog.Warn("handle request data error", "error", err)
+				return nil
+			}
+			return responseData
+		}
+	}
+	return nil
+}
+
+func sendContext(ctx context.Context, transport Transport, requestData []byte) ([]byte, error) {
+	if t, ok := transport.(ContextTransport); ok {
+		return t.SendContext(ctx, requestData)
+	}
+	return transport.Send(requestData)
+}
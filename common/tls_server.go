@@ -0,0 +1,184 @@
+package common
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+// TLSServer Modbus/TCP Security 从站服务，使用 TLS 1.2 及以上版本并要求客户端证书（双向认证），
+// 客户端证书中的 Modbus Role 通过 context 传递给实现 ContextTransport 的设备，见 RoleFromContext
+type TLSServer struct {
+	// IdleTimeout 连接上没有请求的最长时间，超过后关闭连接，零值表示不超时
+	IdleTimeout time.Duration
+
+	config   *tls.Config
+	devices  []*ModbusDevice
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+}
+
+// NewTLSServer 创建 TLS 从站服务，config 中应设置服务器证书和用于验证客户端证书的 ClientCAs，
+// ClientAuth 固定为 tls.RequireAndVerifyClientCert
+func NewTLSServer(config *tls.Config) *TLSServer {
+	config = secureTLSConfig(config)
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	return &TLSServer{
+		IdleTimeout: tcpIdleTimeout,
+		config:      config,
+		devices:     make([]*ModbusDevice, 0),
+		conns:       make(map[net.Conn]struct{}),
+	}
+}
+
+// Enroll 添加从站设备，Modbus/TCP Security 只支持 MBAP 帧
+func (s *TLSServer) Enroll(device *ModbusDevice) {
+	if device.FrameType != FrameTypeMBAP {
+		panic("tls server only supports MBAP devices")
+	}
+	for _, dev := range s.devices {
+		if dev.SlaveId == device.SlaveId {
+			panic("device already exists")
+		}
+	}
+	s.devices = append(s.devices, device)
+}
+
+// ListenAndServe 监听 address 并处理请求，直到 Close 被调用，address 为空时监听 SecurePort
+func (s *TLSServer) ListenAndServe(address string) error {
+	if address == "" {
+		address = fmt.Sprintf(":%d", SecurePort)
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve 在 listener 上接受 TLS 连接并处理请求，直到 Close 被调用
+func (s *TLSServer) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = listener.Close()
+		return net.ErrClosed
+	}
+	s.listener = listener
+	s.mu.Unlock()
+
+	tlsListener := tls.NewListener(listener, s.config)
+	for {
+		conn, err := tlsListener.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return nil
+			}
+			return err
+		}
+		if !s.track(conn) {
+			_ = conn.Close()
+			return nil
+		}
+		go s.serveConn(conn.(*tls.Conn))
+	}
+}
+
+// Addr 返回监听地址，未开始监听时返回 nil
+func (s *TLSServer) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// serveConn 完成握手并读取客户端证书中的角色，之后逐帧处理请求
+func (s *TLSServer) serveConn(conn *tls.Conn) {
+	defer s.untrack(conn)
+	remote := conn.RemoteAddr()
+	if s.IdleTimeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(s.IdleTimeout))
+	}
+	if err := conn.Handshake(); err != nil {
+		slog.Warn("tls handshake error", "remote", remote, "error", err)
+		return
+	}
+	state := conn.ConnectionState()
+	role, err := ParseModbusRole(state.PeerCertificates[0])
+	if err != nil {
+		slog.Warn("invalid client certificate", "remote", remote, "error", err)
+		return
+	}
+	slog.Info("tls connection opened", "remote", remote, "role", role)
+	ctx := WithRole(context.Background(), role)
+
+	frame := &MBAPFrame{}
+	for {
+		if s.IdleTimeout > 0 {
+			_ = conn.SetReadDeadline(time.Now().Add(s.IdleTimeout))
+		}
+		if err = frame.ReadFromConn(conn); err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				slog.Warn("tls connection closed", "remote", remote, "error", err)
+			}
+			return
+		}
+		requestData := frame.ToBytes()
+		frame.Release()
+		responseData := dispatchRequestContext(ctx, s.devices, FrameTypeMBAP, requestData[mbapHeaderSize-1], requestData)
+		if len(responseData) == 0 {
+			continue
+		}
+		if _, err = conn.Write(responseData); err != nil {
+			slog.Warn("write response data error", "remote", remote, "error", err)
+			return
+		}
+	}
+}
+
+// Close 停止监听并关闭所有连接
+func (s *TLSServer) Close() (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	return
+}
+
+func (s *TLSServer) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// track 记录连接，服务已关闭时返回 false
+func (s *TLSServer) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *TLSServer) untrack(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+	_ = conn.Close()
+}
@@ -0,0 +1,199 @@
+package common_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// testCA 测试用的本地 CA
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue 签发服务器证书 (role 为空) 或带 Modbus Role 扩展的客户端证书
+func (ca *testCA) issue(t *testing.T, name string, role string, server bool) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if server {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	} else {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		ext, err := common.MarshalModbusRole(role)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template.ExtraExtensions = []pkix.Extension{ext}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestTLSServer(t *testing.T) {
+	ca := newTestCA(t, "modbus test ca")
+	foreignCA := newTestCA(t, "foreign ca")
+
+	store := slave.NewMemoryDataStore()
+	device := slave.NewModbusTCPSlave(1, &slave.DeviceInfo{}, store)
+	var mu sync.Mutex
+	var roles []string
+	// Operator 可以读写，其它角色只能读取
+	err := device.SetAuthorizer(func(ctx context.Context, request *common.ProtocolDataUnit) bool {
+		role, _ := common.RoleFromContext(ctx)
+		mu.Lock()
+		roles = append(roles, role)
+		mu.Unlock()
+		return role == "Operator" || request.FunctionCode == common.FuncCodeReadHoldingRegisters
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := common.NewTLSServer(&tls.Config{
+		Certificates: []tls.Certificate{ca.issue(t, "server", "", true)},
+		ClientCAs:    ca.pool,
+	})
+	server.Enroll(&device.ModbusDevice)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(listener) }()
+	t.Cleanup(func() {
+		_ = server.Close()
+		if err := <-done; err != nil {
+			t.Errorf("serve: %v", err)
+		}
+	})
+
+	newMaster := func(certificates ...tls.Certificate) *master.ModbusMaster {
+		client := common.NewTCPClient(listener.Addr().String())
+		client.Timeout = 2 * time.Second
+		client.TLSConfig = &tls.Config{
+			RootCAs: ca.pool,
+			// 总是发送给定的证书，即使它不是由服务器接受的 CA 签发的，由服务器完成验证
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				if len(certificates) == 0 {
+					return &tls.Certificate{}, nil
+				}
+				return &certificates[0], nil
+			},
+		}
+		t.Cleanup(func() { _ = client.Close() })
+		return master.NewModbusTCPMaster(&client)
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		m := newMaster(ca.issue(t, "operator", "Operator", false))
+		if err := m.WriteSingleRegister(1, 10, 1234); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		values, err := m.ReadHoldingRegisterValues(1, 10, 1)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if values[0] != 1234 {
+			t.Fatalf("read %v, want 1234", values[0])
+		}
+	})
+
+	t.Run("role from context", func(t *testing.T) {
+		mu.Lock()
+		roles = nil
+		mu.Unlock()
+		m := newMaster(ca.issue(t, "viewer", "Viewer", false))
+		if _, err := m.ReadHoldingRegisterValues(1, 10, 1); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(roles) != 1 || roles[0] != "Viewer" {
+			t.Fatalf("authorizer saw roles %q, want [Viewer]", roles)
+		}
+	})
+
+	t.Run("unauthorized function code", func(t *testing.T) {
+		m := newMaster(ca.issue(t, "viewer", "Viewer", false))
+		err := m.WriteSingleRegister(1, 10, 1)
+		var mbError *common.Error
+		if !errors.As(err, &mbError) || mbError.ExceptionCode != common.ExceptionCodeIllegalFunction {
+			t.Fatalf("write: got %v, want illegal function exception", err)
+		}
+		if v := store.Read(slave.PointTypeHoldingRegister, 10); v != 1234 {
+			t.Fatalf("register 10 = %v after unauthorized write, want 1234", v)
+		}
+	})
+
+	t.Run("client without certificate", func(t *testing.T) {
+		m := newMaster()
+		if _, err := m.ReadHoldingRegisterValues(1, 10, 1); err == nil {
+			t.Fatal("read succeeded without a client certificate")
+		}
+	})
+
+	t.Run("client certificate from foreign ca", func(t *testing.T) {
+		m := newMaster(foreignCA.issue(t, "intruder", "Operator", false))
+		if _, err := m.ReadHoldingRegisterValues(1, 10, 1); err == nil {
+			t.Fatal("read succeeded with a certificate from a foreign ca")
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -11,8 +12,15 @@ const (
 	FuncCodeReadInputRegisters         = 4
 	FuncCodeWriteSingleCoil            = 5
 	FuncCodeWriteSingleRegister        = 6
+	FuncCodeReadExceptionStatus        = 7
+	FuncCodeDiagnostics                = 8
+	FuncCodeGetCommEventCounter        = 11
+	FuncCodeGetCommEventLog            = 12
 	FuncCodeWriteMultipleCoils         = 15
 	FuncCodeWriteMultipleRegisters     = 16
+	FuncCodeReportServerID             = 17
+	FuncCodeReadFileRecord             = 20
+	FuncCodeWriteFileRecord            = 21
 	FuncCodeMaskWriteRegister          = 22
 	FuncCodeReadWriteMultipleRegisters = 23
 	FuncCodeReadFIFOQueue              = 24
@@ -31,13 +39,19 @@ var AvailableWriteFunctionCodes = []byte{
 	FuncCodeWriteSingleRegister,
 	FuncCodeWriteMultipleCoils,
 	FuncCodeWriteMultipleRegisters,
+	FuncCodeWriteFileRecord,
+	FuncCodeMaskWriteRegister,
 }
 
+// BroadcastSlaveId 广播地址，从站执行写请求但不返回响应，只用于 RTU 和 ASCII 帧，MBAP 帧中按普通单元 ID 处理
+const BroadcastSlaveId = 0
+
 type FrameType string
 
 const (
-	FrameTypeMBAP = "MBAP"
-	FrameTypeRTU  = "RTU"
+	FrameTypeMBAP  = "MBAP"
+	FrameTypeRTU   = "RTU"
+	FrameTypeASCII = "ASCII"
 )
 
 const (
@@ -47,19 +61,22 @@ const (
 	ExceptionCodeServerDeviceFailure                = 4
 	ExceptionCodeAcknowledge                        = 5
 	ExceptionCodeServerDeviceBusy                   = 6
+	ExceptionCodeNegativeAcknowledge                = 7
 	ExceptionCodeMemoryParityError                  = 8
 	ExceptionCodeGatewayPathUnavailable             = 10
 	ExceptionCodeGatewayTargetDeviceFailedToRespond = 11
 )
 
 type DeviceIdentification struct {
-	VendorName          string //厂商名称 0x00
-	ProductCode         string //产品编号 0x01
-	ProductVersion      string //产品编号 0x02
-	VendorUrl           string //厂商网址 0x03
-	ProductName         string //产品名称 0x04
-	ModelName           string //模式名称 0x05
-	UserApplicationName string //用户应用名称 0x06
+	VendorName          string          //厂商名称 0x00
+	ProductCode         string          //产品编号 0x01
+	ProductVersion      string          //产品版本 0x02
+	VendorUrl           string          //厂商网址 0x03
+	ProductName         string          //产品名称 0x04
+	ModelName           string          //模式名称 0x05
+	UserApplicationName string          //用户应用名称 0x06
+	PrivateObjects      map[byte][]byte //扩展对象 0x80-0xFF
+	ConformityLevel     byte            //一致性等级，仅在读取时由从站返回
 }
 
 // Error Modbus 错误定义
@@ -84,6 +101,8 @@ func (e *Error) Error() string {
 		name = "acknowledge"
 	case ExceptionCodeServerDeviceBusy:
 		name = "server device busy"
+	case ExceptionCodeNegativeAcknowledge:
+		name = "negative acknowledge"
 	case ExceptionCodeMemoryParityError:
 		name = "memory parity error"
 	case ExceptionCodeGatewayPathUnavailable:
@@ -108,6 +127,16 @@ type Transport interface {
 	Send(requestData []byte) (responseData []byte, err error)
 }
 
+// ContextTransport 支持携带 context 的通信层，从站服务通过 context 传递连接信息，如 TLS 客户端的 Modbus Role
+type ContextTransport interface {
+	SendContext(ctx context.Context, requestData []byte) (responseData []byte, err error)
+}
+
+// Transmitter 支持只发送请求、不读取响应的通信层，用于仅监听模式等没有响应的请求
+type Transmitter interface {
+	Transmit(requestData []byte) error
+}
+
 type ModbusDevice struct {
 	SlaveId   uint8
 	FrameType FrameType
@@ -0,0 +1,153 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	udpTimeout    = 1 * time.Second
+	udpRetries    = 2
+	udpBufferSize = 1024
+)
+
+// DefaultUDPDrainTimeout 没有事务 ID 的帧格式（如 RTU over UDP）丢弃迟到响应的默认等待时间
+const DefaultUDPDrainTimeout = 100 * time.Millisecond
+
+// UDPClient UDP 客户端，请求超时未收到匹配的响应时重发，迟到的、不匹配的数据报被丢弃
+type UDPClient struct {
+	Address string
+	// Timeout 每次发送后等待响应的时间
+	Timeout time.Duration
+	// Retries 超时后重发的次数
+	Retries int
+	// DrainTimeout 存在未收到响应的发送（超时或重发）时，下一次发送前等待并丢弃迟到响应的最长时间，
+	// 用于 RTU 等没有事务 ID 的帧格式，避免迟到的重复响应被当作下一个请求的响应，零值表示不等待，
+	// 可设置为 DefaultUDPDrainTimeout
+	DrainTimeout time.Duration
+
+	mu          sync.Mutex
+	conn        *net.UDPConn
+	buffer      []byte
+	outstanding int // 尚未收到响应的发送次数
+}
+
+func NewUDPClient(address string) UDPClient {
+	return UDPClient{
+		Address: address,
+		Timeout: udpTimeout,
+		Retries: udpRetries,
+	}
+}
+
+// Send 发送数据报，并返回第一个 match 为 true 的响应数据报，match 为 nil 时只发送不读取。
+// 超时未收到匹配的响应时重发 requestData，最多重发 Retries 次，非幂等的写请求可能被从站重复执行
+func (t *UDPClient) Send(requestData []byte, match func(responseData []byte) bool) (responseData []byte, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err = t.connect(); err != nil {
+		return
+	}
+	if err = t.drain(); err != nil {
+		return
+	}
+	for attempt := 0; ; attempt++ {
+		if _, err = t.conn.Write(requestData); err != nil {
+			return
+		}
+		if match == nil {
+			return
+		}
+		t.outstanding++
+		responseData, err = t.receive(match)
+		if err == nil {
+			t.outstanding--
+			return
+		}
+		if !errors.Is(err, os.ErrDeadlineExceeded) || attempt >= t.Retries {
+			return
+		}
+	}
+}
+
+// drain 在 DrainTimeout 内读取并丢弃之前的发送迟到的响应，直至所有发送都有了响应
+func (t *UDPClient) drain() error {
+	if t.outstanding <= 0 || t.DrainTimeout <= 0 {
+		t.outstanding = 0
+		return nil
+	}
+	if err := t.conn.SetReadDeadline(time.Now().Add(t.DrainTimeout)); err != nil {
+		return err
+	}
+	for t.outstanding > 0 {
+		if _, err := t.conn.Read(t.buffer); err != nil {
+			break
+		}
+		t.outstanding--
+	}
+	t.outstanding = 0
+	return nil
+}
+
+// receive 在 Timeout 内读取数据报，直到 match 返回 true
+func (t *UDPClient) receive(match func(responseData []byte) bool) ([]byte, error) {
+	var deadline time.Time
+	if t.Timeout > 0 {
+		deadline = time.Now().Add(t.Timeout)
+	}
+	if err := t.conn.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+	for {
+		n, err := t.conn.Read(t.buffer)
+		if err != nil {
+			return nil, err
+		}
+		if match(t.buffer[:n]) {
+			responseData := make([]byte, n)
+			copy(responseData, t.buffer[:n])
+			return responseData, nil
+		}
+	}
+}
+
+// Connect 封装给外部使用
+func (t *UDPClient) Connect() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.connect()
+}
+
+// 如果不存在连接，创建连接，连接后只接收来自 Address 的数据报
+func (t *UDPClient) connect() error {
+	if t.conn == nil {
+		addr, err := net.ResolveUDPAddr("udp", t.Address)
+		if err != nil {
+			return fmt.Errorf("modbus: resolve udp address '%v': %w", t.Address, err)
+		}
+		conn, err := net.DialUDP("udp", nil, addr)
+		if err != nil {
+			return err
+		}
+		t.conn = conn
+		t.buffer = make([]byte, udpBufferSize)
+	}
+	return nil
+}
+
+// Close 封装给外部使用
+func (t *UDPClient) Close() (err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conn != nil {
+		err = t.conn.Close()
+		t.conn = nil
+	}
+	return
+}
@@ -0,0 +1,93 @@
+package master
+
+import (
+	"io"
+	"net"
+	"time"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// NewModbusASCIIMasterWithConfig 使用串口配置创建 ASCII 主站
+func NewModbusASCIIMasterWithConfig(config common.SerialConfig) *ModbusMaster {
+	serialClient := common.NewSerialClient(config)
+	return NewModbusASCIIMaster(&serialClient)
+}
+
+func NewModbusASCIIMaster(client *common.SerialClient) *ModbusMaster {
+	return NewModbusMaster(&common.ASCIIMessage{}, NewASCIITransport(client))
+}
+
+// NewModbusASCIIOverTCPMasterWithAddress 创建通过 TCP 连接串口服务器的 ASCII 主站
+func NewModbusASCIIOverTCPMasterWithAddress(address string) *ModbusMaster {
+	tcpClient := common.NewTCPClient(address)
+	return NewModbusASCIIOverTCPMaster(&tcpClient)
+}
+
+func NewModbusASCIIOverTCPMaster(client *common.TCPClient) *ModbusMaster {
+	return NewModbusMaster(&common.ASCIIMessage{}, NewASCIIOverTCPTransport(client))
+}
+
+// ASCIITransport Modbus ASCII 串口传输定义，实现 Transport 接口
+type ASCIITransport struct {
+	client *common.SerialClient
+	// CharTimeout 响应帧中两个字符的最大间隔
+	CharTimeout time.Duration
+	// Delimiter 请求帧的结束符，从站通过诊断子功能 0x03 修改了结束符时设置为相同的字符，零值表示 LF
+	Delimiter byte
+}
+
+func NewASCIITransport(client *common.SerialClient) *ASCIITransport {
+	return &ASCIITransport{client: client, CharTimeout: common.DefaultASCIICharTimeout}
+}
+
+// Send 发送数据到串口，并读取到 CRLF 为止的响应帧
+func (t *ASCIITransport) Send(requestData []byte) (responseData []byte, err error) {
+	err = t.client.Send(withASCIIDelimiter(requestData, t.Delimiter), func(r io.Reader) (e error) {
+		responseData, e = common.ReadASCIIFrame(r, t.CharTimeout)
+		return
+	})
+	return
+}
+
+// Transmit 发送数据到串口，不等待响应
+func (t *ASCIITransport) Transmit(requestData []byte) error {
+	return t.client.Send(withASCIIDelimiter(requestData, t.Delimiter), nil)
+}
+
+// ASCIIOverTCPTransport 通过 TCP 透传的 Modbus ASCII 传输定义，用于串口服务器，实现 Transport 接口
+type ASCIIOverTCPTransport struct {
+	client *common.TCPClient
+	// CharTimeout 响应帧中两个字符的最大间隔
+	CharTimeout time.Duration
+	// Delimiter 请求帧的结束符，从站通过诊断子功能 0x03 修改了结束符时设置为相同的字符，零值表示 LF
+	Delimiter byte
+}
+
+func NewASCIIOverTCPTransport(client *common.TCPClient) *ASCIIOverTCPTransport {
+	return &ASCIIOverTCPTransport{client: client, CharTimeout: common.DefaultASCIICharTimeout}
+}
+
+// Send 发送数据到服务器，并读取到 CRLF 为止的响应帧
+func (t *ASCIIOverTCPTransport) Send(requestData []byte) (responseData []byte, err error) {
+	err = t.client.Send(withASCIIDelimiter(requestData, t.Delimiter), func(conn net.Conn) (e error) {
+		responseData, e = common.ReadASCIIFrame(conn, t.CharTimeout)
+		return
+	})
+	return
+}
+
+// Transmit 发送数据到服务器，不等待响应
+func (t *ASCIIOverTCPTransport) Transmit(requestData []byte) error {
+	return t.client.Send(withASCIIDelimiter(requestData, t.Delimiter), nil)
+}
+
+// withASCIIDelimiter 将编码后以 CRLF 结束的请求帧的 LF 替换为 delimiter，delimiter 为零值时不替换
+func withASCIIDelimiter(requestData []byte, delimiter byte) []byte {
+	if delimiter == 0 || len(requestData) == 0 {
+		return requestData
+	}
+	data := append([]byte(nil), requestData...)
+	data[len(data)-1] = delimiter
+	return data
+}
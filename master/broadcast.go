@@ -0,0 +1,76 @@
+package master
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// Broadcast 向所有从站 (地址 0) 发送写请求，发送后不等待响应，等待 TurnaroundDelay 后返回。
+// 只用于 RTU 和 ASCII 帧，MBAP 帧中单元 ID 0 为普通地址，服务器会返回响应，因此返回错误
+func (c *ModbusMaster) Broadcast(request *common.ProtocolDataUnit) (err error) {
+	if _, ok := c.message.(*common.MBAPMessage); ok {
+		err = fmt.Errorf("modbus: broadcast is not supported with MBAP frames")
+		return
+	}
+	if !slices.Contains(common.AvailableWriteFunctionCodes, request.FunctionCode) {
+		err = fmt.Errorf("modbus: function code '%v' is not allowed in broadcast", request.FunctionCode)
+		return
+	}
+	if err = c.transmit(common.BroadcastSlaveId, request); err != nil {
+		return
+	}
+	time.Sleep(c.TurnaroundDelay)
+	return
+}
+
+// BroadcastWriteSingleCoil 广播写单个线圈 (功能码 0x05)
+func (c *ModbusMaster) BroadcastWriteSingleCoil(address uint16, state bool) error {
+	value := uint16(0x0000)
+	if state {
+		value = 0xFF00
+	}
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteSingleCoil}
+	request.LoadData(address, value)
+	return c.Broadcast(request)
+}
+
+// BroadcastWriteSingleRegister 广播写单个寄存器 (功能码 0x06)
+func (c *ModbusMaster) BroadcastWriteSingleRegister(address, value uint16) error {
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteSingleRegister}
+	request.LoadData(address, value)
+	return c.Broadcast(request)
+}
+
+// BroadcastWriteMultipleCoils 广播写多个线圈 (功能码 0x0F)
+func (c *ModbusMaster) BroadcastWriteMultipleCoils(address uint16, values []bool) error {
+	quantity := uint16(len(values))
+	if quantity < 1 || quantity > 1968 {
+		return fmt.Errorf("modbus: quantity '%v' is out of range [1, 1968]", quantity)
+	}
+	outputValues := common.NewBitVectorFromBooleans(values).ToBytes()
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteMultipleCoils}
+	request.LoadData(address, quantity).Append(byte(len(outputValues))).Append(outputValues...)
+	return c.Broadcast(request)
+}
+
+// BroadcastWriteMultipleRegisters 广播写多个寄存器 (功能码 0x10)
+func (c *ModbusMaster) BroadcastWriteMultipleRegisters(address uint16, registers []*common.Register) error {
+	quantity := len(registers)
+	if quantity < 1 || quantity > 123 {
+		return fmt.Errorf("modbus: quantity '%v' is out of range [1, 123]", quantity)
+	}
+	value := *common.RegistersToBytes(registers)
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteMultipleRegisters}
+	request.LoadData(address, uint16(quantity)).Append(byte(len(value))).Append(value...)
+	return c.Broadcast(request)
+}
+
+// BroadcastMaskWriteRegister 广播屏蔽写寄存器 (功能码 0x16)
+func (c *ModbusMaster) BroadcastMaskWriteRegister(address, andMask, orMask uint16) error {
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeMaskWriteRegister}
+	request.LoadData(address, andMask, orMask)
+	return c.Broadcast(request)
+}
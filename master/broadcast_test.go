@@ -0,0 +1,79 @@
+package master_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// TestBroadcastOverMBAP MBAP 帧中单元 ID 0 会得到服务器的响应，广播被拒绝，连接上不残留未读取的响应
+func TestBroadcastOverMBAP(t *testing.T) {
+	store := slave.NewMemoryDataStore()
+	address := startTCPSlave(t, 1, store)
+	m := master.NewModbusTCPMasterWithAddress(address)
+
+	if err := m.WriteSingleRegister(1, 10, 5); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := m.BroadcastWriteSingleRegister(10, 7); err == nil {
+		t.Fatal("broadcast over MBAP: got nil error")
+	}
+	for i := 0; i < 3; i++ {
+		values, err := m.ReadHoldingRegisterValues(1, 10, 1)
+		if err != nil {
+			t.Fatalf("read %v after broadcast: %v", i, err)
+		}
+		if values[0] != 5 {
+			t.Fatalf("read %v after broadcast = %v, want 5", i, values[0])
+		}
+	}
+	if v := store.Read(slave.PointTypeHoldingRegister, 10); v != 5 {
+		t.Errorf("store register 10 = %v, want 5", v)
+	}
+}
+
+// TestBroadcastOverRTU 广播写入所有从站，发送后不读取响应，等待 TurnaroundDelay 后返回
+func TestBroadcastOverRTU(t *testing.T) {
+	store1, store2 := slave.NewMemoryDataStore(), slave.NewMemoryDataStore()
+	slave1 := slave.NewModbusRTUOverTCPSlave(1, &slave.DeviceInfo{}, store1)
+	slave2 := slave.NewModbusRTUOverTCPSlave(2, &slave.DeviceInfo{}, store2)
+	client := common.NewTCPClient(startNetServer(t, &slave1.ModbusDevice, &slave2.ModbusDevice))
+	client.Timeout = 2 * time.Second
+	m := master.NewModbusRTUOverTCPMaster(&client)
+	m.TurnaroundDelay = 200 * time.Millisecond
+
+	start := time.Now()
+	if err := m.BroadcastWriteMultipleRegisters(10, common.NewRegistersFromUInt16s([]uint16{7, 8})); err != nil {
+		t.Fatal(err)
+	}
+	// 读取响应会等待到读取超时
+	if elapsed := time.Since(start); elapsed < m.TurnaroundDelay || elapsed >= client.Timeout {
+		t.Fatalf("broadcast returned after %v, want about %v", elapsed, m.TurnaroundDelay)
+	}
+	for i, store := range []*slave.MemoryDataStore{store1, store2} {
+		if v := store.Read(slave.PointTypeHoldingRegister, 11); v != 8 {
+			t.Errorf("slave %v register 11 = %v, want 8", i+1, v)
+		}
+	}
+	// 连接上不残留响应，后续请求正常
+	for slaveId := byte(1); slaveId <= 2; slaveId++ {
+		values, err := m.ReadHoldingRegisterValues(slaveId, 10, 2)
+		if err != nil || !slices.Equal(values, []uint16{7, 8}) {
+			t.Fatalf("slave %v registers = %v, %v, want [7 8]", slaveId, values, err)
+		}
+	}
+
+	read := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadHoldingRegisters}
+	read.LoadData(10, 1)
+	if err := m.Broadcast(read); err == nil {
+		t.Fatal("broadcast read: got nil error")
+	}
+	if err := slave1.Handler.HandleBroadcast(context.Background(), read); err == nil {
+		t.Fatal("handle broadcast read: got nil error")
+	}
+}
@@ -0,0 +1,35 @@
+package master
+
+import (
+	"fmt"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// CANopenGeneralReference 发送 CANopen General Reference 请求，data 与返回的 result 均为不含 MEI 类型的 CANopen 数据。
+// RTU 模式下响应长度无法由协议确定，需通过 SetResponseLength 设置计算函数，否则返回错误
+// Request:
+//
+//	Function code         : 1 byte (0x2B)
+//	MEI type              : 1 byte (0x0D)
+//	MEI type specific data: N bytes
+//
+// Response:
+//
+//	Function code         : 1 byte (0x2B)
+//	MEI type              : 1 byte (0x0D)
+//	MEI type specific data: N bytes
+func (c *ModbusMaster) CANopenGeneralReference(slaveId byte, data []byte) (result []byte, err error) {
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadDeviceIdentification}
+	request.Append(common.MEITypeCANopenGeneralReference).Append(data...)
+	response, err := c.send(slaveId, request)
+	if err != nil {
+		return
+	}
+	if response.Data[0] != common.MEITypeCANopenGeneralReference {
+		err = fmt.Errorf("modbus: response mei type '%v' does not match request '%v'", response.Data[0], common.MEITypeCANopenGeneralReference)
+		return
+	}
+	result = response.Data[1:]
+	return
+}
@@ -0,0 +1,51 @@
+package master_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// TestCANopenGeneralReferenceOverRTU RTU 模式下未设置响应长度函数时返回错误，设置后按 CANopen 数据长度读取响应
+func TestCANopenGeneralReferenceOverRTU(t *testing.T) {
+	s := slave.NewModbusRTUOverTCPSlave(1, &slave.DeviceInfo{}, slave.NewMemoryDataStore())
+	err := s.SetCANopenHandler(func(ctx context.Context, request []byte) ([]byte, error) {
+		return append([]byte{byte(len(request))}, request...), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := startNetServer(t, &s.ModbusDevice)
+	m := master.NewModbusRTUOverTCPMasterWithAddress(address)
+	data := []byte{0x40, 0x00, 0x10}
+
+	if _, err := m.CANopenGeneralReference(1, data); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Fatalf("without response length: got %v, want unknown length error", err)
+	}
+
+	// 从站地址，功能码，MEI 类型，数据长度，数据，CRC
+	err = m.SetResponseLength(func(requestData []byte, responseData []byte) int {
+		if requestData[1] != common.FuncCodeReadDeviceIdentification || requestData[2] != common.MEITypeCANopenGeneralReference {
+			return 0
+		}
+		if len(responseData) < 4 {
+			return 4
+		}
+		return 4 + int(responseData[3]) + 2
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.CANopenGeneralReference(1, data)
+	if err != nil {
+		t.Fatalf("with response length: %v", err)
+	}
+	if want := append([]byte{3}, data...); !bytes.Equal(result, want) {
+		t.Fatalf("result = %v, want %v", result, want)
+	}
+}
@@ -0,0 +1,92 @@
+package master_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+func TestCommEventLog(t *testing.T) {
+	s := slave.NewModbusRTUOverTCPSlave(1, &slave.DeviceInfo{}, slave.NewMemoryDataStore())
+	// 功能码 65 以请求数据中的异常码响应
+	err := s.RegisterFunction(65, func(ctx context.Context, request *common.ProtocolDataUnit) (*common.ProtocolDataUnit, error) {
+		return nil, &common.Error{FunctionCode: request.FunctionCode, ExceptionCode: request.Data[0]}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := master.NewModbusRTUOverTCPMasterWithAddress(startNetServer(t, &s.ModbusDevice))
+
+	if err = s.SetExceptionStatus(0x6D); err != nil {
+		t.Fatal(err)
+	}
+	status, err := m.ReadExceptionStatus(1)
+	if err != nil || status != 0x6D {
+		t.Fatalf("exception status = %#x, %v, want 0x6d", status, err)
+	}
+
+	counter, err := m.GetCommEventCounter(1)
+	if err != nil || counter.Status != 0 || counter.EventCount != 1 {
+		t.Fatalf("comm event counter = %+v, %v, want status 0 and count 1", counter, err)
+	}
+	if err = s.SetBusy(true); err != nil {
+		t.Fatal(err)
+	}
+	if counter, err = m.GetCommEventCounter(1); err != nil || counter.Status != common.CommEventStatusBusy || !counter.Busy() {
+		t.Fatalf("busy comm event counter = %+v, %v", counter, err)
+	}
+	if log, err := m.GetCommEventLog(1); err != nil || log.Status != common.CommEventStatusBusy || !log.Busy() {
+		t.Fatalf("busy comm event log = %+v, %v", log, err)
+	}
+	if err = s.SetBusy(false); err != nil {
+		t.Fatal(err)
+	}
+
+	// 每个请求记录接收和发送两个事件，之前的事件被覆盖
+	for i := 0; i < 40; i++ {
+		if _, err = m.ReadHoldingRegisterValues(1, 0, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exceptions := []byte{
+		common.ExceptionCodeIllegalFunction,
+		common.ExceptionCodeServerDeviceFailure,
+		common.ExceptionCodeServerDeviceBusy,
+		common.ExceptionCodeNegativeAcknowledge,
+	}
+	for _, exceptionCode := range exceptions {
+		if _, err = m.Execute(1, &common.ProtocolDataUnit{FunctionCode: 65, Data: []byte{exceptionCode}}); err == nil {
+			t.Fatalf("exception code %v: got nil error", exceptionCode)
+		}
+	}
+
+	log, err := m.GetCommEventLog(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 最新的在前：本次请求的接收事件，随后为各异常响应的发送事件
+	want := []byte{
+		common.CommEventReceive,
+		common.CommEventSend | common.CommEventSendServerNAKException, common.CommEventReceive,
+		common.CommEventSend | common.CommEventSendServerBusyException, common.CommEventReceive,
+		common.CommEventSend | common.CommEventSendServerAbortException, common.CommEventReceive,
+		common.CommEventSend | common.CommEventSendReadException, common.CommEventReceive,
+	}
+	for len(want) < 64 {
+		want = append(want, common.CommEventSend, common.CommEventReceive)
+	}
+	if !bytes.Equal(log.Events, want[:64]) {
+		t.Fatalf("events = % x, want % x", log.Events, want)
+	}
+	// 异常响应和 Get Comm Event Counter/Log 不计入事件计数
+	if log.EventCount != 41 {
+		t.Fatalf("event count = %v, want 41", log.EventCount)
+	}
+	if counters := s.Handler.Counters(); log.MessageCount != counters.BusMessageCount {
+		t.Fatalf("message count = %v, want %v", log.MessageCount, counters.BusMessageCount)
+	}
+}
@@ -0,0 +1,93 @@
+package master_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// TestReadDeviceIdentificationStream 对象总长度超过一个 PDU 时，主站按后续标识和下一对象 ID 继续读取
+func TestReadDeviceIdentificationStream(t *testing.T) {
+	identification := &common.DeviceIdentification{
+		VendorName:     "vendor",
+		ProductCode:    "code",
+		ProductVersion: "1.0",
+		ProductName:    "product",
+	}
+	for id := byte(0x80); id < 0x84; id++ {
+		if err := identification.SetObject(id, bytes.Repeat([]byte{id}, 100)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := slave.NewModbusTCPSlave(1, &slave.DeviceInfo{Identification: identification}, slave.NewMemoryDataStore())
+	m := master.NewModbusTCPMasterWithAddress(startNetServer(t, &s.ModbusDevice))
+
+	info, err := m.ReadDeviceIdentificationStream(1, common.ReadDeviceIDCodeExtended)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 每个私有对象连同标识和长度共 102 字节，一个响应最多容纳两个，需分两次读取
+	if requests := s.Handler.Counters().ServerMessageCount; requests != 2 {
+		t.Fatalf("requests = %v, want 2", requests)
+	}
+	if info.ConformityLevel != common.ConformityLevelExtended|common.ConformityLevelIndividualAccess {
+		t.Fatalf("conformity level = %#x", info.ConformityLevel)
+	}
+	if info.VendorName != "vendor" || info.ProductName != "product" {
+		t.Fatalf("standard objects = %+v", info)
+	}
+	for id := byte(0x80); id < 0x84; id++ {
+		if value, ok := info.Object(id); !ok || !bytes.Equal(value, bytes.Repeat([]byte{id}, 100)) {
+			t.Fatalf("object %#x = %v, %v", id, len(value), ok)
+		}
+	}
+
+	// 基本读取码只返回 0x00-0x02
+	info, err = m.ReadDeviceIdentification(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ProductVersion != "1.0" || info.ProductName != "" || len(info.PrivateObjects) != 0 {
+		t.Fatalf("basic identification = %+v", info)
+	}
+}
+
+func TestReadDeviceIdentificationObject(t *testing.T) {
+	identification := &common.DeviceIdentification{VendorName: "vendor", ModelName: "model"}
+	s := slave.NewModbusTCPSlave(1, &slave.DeviceInfo{Identification: identification}, slave.NewMemoryDataStore())
+	m := master.NewModbusTCPMasterWithAddress(startNetServer(t, &s.ModbusDevice))
+
+	value, err := m.ReadDeviceIdentificationObject(1, common.DeviceObjectModelName)
+	if err != nil || string(value) != "model" {
+		t.Fatalf("model name = %q, %v", value, err)
+	}
+	var exception *common.Error
+	if _, err = m.ReadDeviceIdentificationObject(1, common.DeviceObjectProductName); !errors.As(err, &exception) || exception.ExceptionCode != common.ExceptionCodeIllegalDataAddress {
+		t.Fatalf("missing object: got %v, want illegal data address exception", err)
+	}
+}
+
+// TestReadDeviceIdentificationNextObjectNotAdvancing 下一对象 ID 不前进时返回错误，避免无限循环
+func TestReadDeviceIdentificationNextObjectNotAdvancing(t *testing.T) {
+	s := slave.NewModbusTCPSlave(1, &slave.DeviceInfo{}, slave.NewMemoryDataStore())
+	err := s.RegisterFunction(common.FuncCodeReadDeviceIdentification, func(ctx context.Context, request *common.ProtocolDataUnit) (*common.ProtocolDataUnit, error) {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReadDeviceIdentification,
+			Data:         []byte{common.MEITypeReadDeviceIdentification, request.Data[1], 0x81, 0xFF, request.Data[2], 0x01, request.Data[2], 0x01, 'x'},
+		}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := master.NewModbusTCPMasterWithAddress(startNetServer(t, &s.ModbusDevice))
+
+	if _, err = m.ReadDeviceIdentification(1); err == nil || !strings.Contains(err.Error(), "does not advance") {
+		t.Fatalf("got %v, want does not advance error", err)
+	}
+}
@@ -0,0 +1,248 @@
+package master
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// Diagnostics
+// Request:
+//
+//	Function code         : 1 byte (0x08)
+//	Sub-function          : 2 bytes
+//	Data                  : N x 2 bytes
+//
+// Response:
+//
+//	Function code         : 1 byte (0x08)
+//	Sub-function          : 2 bytes
+//	Data                  : N x 2 bytes
+func (c *ModbusMaster) Diagnostics(slaveId byte, subFunction uint16, data []byte) (result []byte, err error) {
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeDiagnostics}
+	request.LoadData(subFunction).Append(data...)
+	response, err := c.send(slaveId, request)
+	if err != nil {
+		return
+	}
+	if len(response.Data) < 2 {
+		err = fmt.Errorf("modbus: response data size '%v' is less than expected '%v'", len(response.Data), 2)
+		return
+	}
+	respValue := binary.BigEndian.Uint16(response.Data)
+	if subFunction != respValue {
+		err = fmt.Errorf("modbus: response sub-function '%v' does not match request '%v'", respValue, subFunction)
+		return
+	}
+	result = response.Data[2:]
+	return
+}
+
+// ReturnQueryData 回送查询数据 (子功能码 0x00)，响应必须与请求数据一致
+func (c *ModbusMaster) ReturnQueryData(slaveId byte, data []byte) (err error) {
+	result, err := c.Diagnostics(slaveId, common.DiagnosticReturnQueryData, data)
+	if err != nil {
+		return
+	}
+	if !bytes.Equal(result, data) {
+		err = fmt.Errorf("modbus: response query data '%x' does not match request '%x'", result, data)
+		return
+	}
+	return
+}
+
+// RestartCommunicationsOption 重启通信 (子功能码 0x01)，clearLog 为 true 时同时清除通信事件日志
+func (c *ModbusMaster) RestartCommunicationsOption(slaveId byte, clearLog bool) (err error) {
+	value := uint16(0x0000)
+	if clearLog {
+		value = 0xFF00
+	}
+	_, err = c.diagnosticValue(slaveId, common.DiagnosticRestartCommunicationsOption, value, true)
+	return
+}
+
+// ReturnDiagnosticRegister 读取诊断寄存器 (子功能码 0x02)
+func (c *ModbusMaster) ReturnDiagnosticRegister(slaveId byte) (uint16, error) {
+	return c.diagnosticValue(slaveId, common.DiagnosticReturnDiagnosticRegister, 0x0000, false)
+}
+
+// ChangeASCIIInputDelimiter 修改 ASCII 模式的结束符 (子功能码 0x03)
+func (c *ModbusMaster) ChangeASCIIInputDelimiter(slaveId byte, delimiter byte) (err error) {
+	_, err = c.diagnosticValue(slaveId, common.DiagnosticChangeASCIIInputDelimiter, uint16(delimiter)<<8, true)
+	return
+}
+
+// ForceListenOnlyMode 强制从站进入仅监听模式 (子功能码 0x04)，从站不返回响应，发送后等待 TurnaroundDelay 再返回
+func (c *ModbusMaster) ForceListenOnlyMode(slaveId byte) (err error) {
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeDiagnostics}
+	request.LoadData(common.DiagnosticForceListenOnlyMode, 0x0000)
+	if err = c.transmit(slaveId, request); err != nil {
+		return
+	}
+	time.Sleep(c.TurnaroundDelay)
+	return
+}
+
+// ClearCountersAndDiagnosticRegister 清除计数器和诊断寄存器 (子功能码 0x0A)
+func (c *ModbusMaster) ClearCountersAndDiagnosticRegister(slaveId byte) (err error) {
+	_, err = c.diagnosticValue(slaveId, common.DiagnosticClearCountersAndDiagnosticRegister, 0x0000, true)
+	return
+}
+
+// ReturnBusMessageCount 读取总线报文计数 (子功能码 0x0B)
+func (c *ModbusMaster) ReturnBusMessageCount(slaveId byte) (uint16, error) {
+	return c.diagnosticValue(slaveId, common.DiagnosticReturnBusMessageCount, 0x0000, false)
+}
+
+// ReturnBusCommunicationErrorCount 读取总线通信错误（CRC 错误）计数 (子功能码 0x0C)
+func (c *ModbusMaster) ReturnBusCommunicationErrorCount(slaveId byte) (uint16, error) {
+	return c.diagnosticValue(slaveId, common.DiagnosticReturnBusCommunicationErrorCount, 0x0000, false)
+}
+
+// ReturnBusExceptionErrorCount 读取异常响应计数 (子功能码 0x0D)
+func (c *ModbusMaster) ReturnBusExceptionErrorCount(slaveId byte) (uint16, error) {
+	return c.diagnosticValue(slaveId, common.DiagnosticReturnBusExceptionErrorCount, 0x0000, false)
+}
+
+// ReturnServerMessageCount 读取从站处理的报文计数 (子功能码 0x0E)
+func (c *ModbusMaster) ReturnServerMessageCount(slaveId byte) (uint16, error) {
+	return c.diagnosticValue(slaveId, common.DiagnosticReturnServerMessageCount, 0x0000, false)
+}
+
+// ReturnServerNoResponseCount 读取从站未响应的报文计数 (子功能码 0x0F)
+func (c *ModbusMaster) ReturnServerNoResponseCount(slaveId byte) (uint16, error) {
+	return c.diagnosticValue(slaveId, common.DiagnosticReturnServerNoResponseCount, 0x0000, false)
+}
+
+// ReturnServerNAKCount 读取否定确认异常计数 (子功能码 0x10)
+func (c *ModbusMaster) ReturnServerNAKCount(slaveId byte) (uint16, error) {
+	return c.diagnosticValue(slaveId, common.DiagnosticReturnServerNAKCount, 0x0000, false)
+}
+
+// ReturnServerBusyCount 读取从站忙异常计数 (子功能码 0x11)
+func (c *ModbusMaster) ReturnServerBusyCount(slaveId byte) (uint16, error) {
+	return c.diagnosticValue(slaveId, common.DiagnosticReturnServerBusyCount, 0x0000, false)
+}
+
+// ReturnBusCharacterOverrunCount 读取字符溢出计数 (子功能码 0x12)
+func (c *ModbusMaster) ReturnBusCharacterOverrunCount(slaveId byte) (uint16, error) {
+	return c.diagnosticValue(slaveId, common.DiagnosticReturnBusCharacterOverrunCount, 0x0000, false)
+}
+
+// ClearOverrunCounterAndFlag 清除字符溢出计数和标志 (子功能码 0x14)
+func (c *ModbusMaster) ClearOverrunCounterAndFlag(slaveId byte) (err error) {
+	_, err = c.diagnosticValue(slaveId, common.DiagnosticClearOverrunCounterAndFlag, 0x0000, true)
+	return
+}
+
+// diagnosticValue 发送携带 2 字节数据的诊断请求并返回 2 字节响应数据，echo 为 true 时要求响应与请求一致
+func (c *ModbusMaster) diagnosticValue(slaveId byte, subFunction uint16, value uint16, echo bool) (result uint16, err error) {
+	data := []byte{byte(value >> 8), byte(value)}
+	response, err := c.Diagnostics(slaveId, subFunction, data)
+	if err != nil {
+		return
+	}
+	if len(response) != 2 {
+		err = fmt.Errorf("modbus: response data size '%v' does not match expected '%v'", len(response)+2, 4)
+		return
+	}
+	result = binary.BigEndian.Uint16(response)
+	if echo && result != value {
+		err = fmt.Errorf("modbus: response value '%v' does not match request '%v'", result, value)
+		return
+	}
+	return
+}
+
+// ReadExceptionStatus
+// Request:
+//
+//	Function code         : 1 byte (0x07)
+//
+// Response:
+//
+//	Function code         : 1 byte (0x07)
+//	Output data           : 1 byte
+func (c *ModbusMaster) ReadExceptionStatus(slaveId byte) (status byte, err error) {
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadExceptionStatus}
+	response, err := c.send(slaveId, request)
+	if err != nil {
+		return
+	}
+	// Fixed response length
+	if len(response.Data) != 1 {
+		err = fmt.Errorf("modbus: response data size '%v' does not match expected '%v'", len(response.Data), 1)
+		return
+	}
+	status = response.Data[0]
+	return
+}
+
+// GetCommEventCounter
+// Request:
+//
+//	Function code         : 1 byte (0x0B)
+//
+// Response:
+//
+//	Function code         : 1 byte (0x0B)
+//	Status                : 2 bytes
+//	Event count           : 2 bytes
+func (c *ModbusMaster) GetCommEventCounter(slaveId byte) (counter *common.CommEventCounter, err error) {
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeGetCommEventCounter}
+	response, err := c.send(slaveId, request)
+	if err != nil {
+		return
+	}
+	// Fixed response length
+	if len(response.Data) != 4 {
+		err = fmt.Errorf("modbus: response data size '%v' does not match expected '%v'", len(response.Data), 4)
+		return
+	}
+	counter = &common.CommEventCounter{
+		Status:     binary.BigEndian.Uint16(response.Data),
+		EventCount: binary.BigEndian.Uint16(response.Data[2:]),
+	}
+	return
+}
+
+// GetCommEventLog
+// Request:
+//
+//	Function code         : 1 byte (0x0C)
+//
+// Response:
+//
+//	Function code         : 1 byte (0x0C)
+//	Byte count            : 1 byte
+//	Status                : 2 bytes
+//	Event count           : 2 bytes
+//	Message count         : 2 bytes
+//	Events                : (0-64) bytes
+func (c *ModbusMaster) GetCommEventLog(slaveId byte) (log *common.CommEventLog, err error) {
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeGetCommEventLog}
+	response, err := c.send(slaveId, request)
+	if err != nil {
+		return
+	}
+	count := int(response.Data[0])
+	length := len(response.Data) - 1
+	if count != length {
+		err = fmt.Errorf("modbus: response data size '%v' does not match count '%v'", length, count)
+		return
+	}
+	if count < 6 || count > 6+64 {
+		err = fmt.Errorf("modbus: response byte count '%v' is out of range [6, 70]", count)
+		return
+	}
+	log = &common.CommEventLog{
+		Status:       binary.BigEndian.Uint16(response.Data[1:]),
+		EventCount:   binary.BigEndian.Uint16(response.Data[3:]),
+		MessageCount: binary.BigEndian.Uint16(response.Data[5:]),
+		Events:       append([]byte(nil), response.Data[7:]...),
+	}
+	return
+}
@@ -0,0 +1,90 @@
+package master_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// startDiagnosticsSlave 启动 RTU over TCP 从站，返回从站和读取超时较短的主站，仅监听模式下的请求很快超时
+func startDiagnosticsSlave(t *testing.T) (*slave.ModbusSlave, *master.ModbusMaster) {
+	t.Helper()
+	s := slave.NewModbusRTUOverTCPSlave(1, &slave.DeviceInfo{}, slave.NewMemoryDataStore())
+	client := common.NewTCPClient(startNetServer(t, &s.ModbusDevice))
+	client.Timeout = 200 * time.Millisecond
+	return s, master.NewModbusRTUOverTCPMaster(&client)
+}
+
+func TestDiagnosticCounters(t *testing.T) {
+	s, m := startDiagnosticsSlave(t)
+	for i := 0; i < 3; i++ {
+		if _, err := m.ReadHoldingRegisterValues(1, 0, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 计数请求本身在读取前已计入总线报文，处理完成后才计入从站报文
+	count, err := m.ReturnBusMessageCount(1)
+	if err != nil || count != 4 {
+		t.Fatalf("bus message count = %v, %v, want 4", count, err)
+	}
+	if count, err = m.ReturnServerMessageCount(1); err != nil || count != 4 {
+		t.Fatalf("server message count = %v, %v, want 4", count, err)
+	}
+
+	var exception *common.Error
+	if _, err = m.Execute(1, &common.ProtocolDataUnit{FunctionCode: 0x41}); !errors.As(err, &exception) {
+		t.Fatalf("unknown function code: got %v, want exception", err)
+	}
+	if count, err = m.ReturnBusExceptionErrorCount(1); err != nil || count != 1 {
+		t.Fatalf("bus exception error count = %v, %v, want 1", count, err)
+	}
+
+	s.Handler.SetDiagnosticRegister(0x1234)
+	if err = m.ClearCountersAndDiagnosticRegister(1); err != nil {
+		t.Fatal(err)
+	}
+	if count, err = m.ReturnBusMessageCount(1); err != nil || count != 1 {
+		t.Fatalf("bus message count after clear = %v, %v, want 1", count, err)
+	}
+	if count, err = m.ReturnBusExceptionErrorCount(1); err != nil || count != 0 {
+		t.Fatalf("bus exception error count after clear = %v, %v, want 0", count, err)
+	}
+	if register, err := m.ReturnDiagnosticRegister(1); err != nil || register != 0 {
+		t.Fatalf("diagnostic register after clear = %#x, %v, want 0", register, err)
+	}
+}
+
+// TestListenOnlyMode 仅监听模式下不响应任何请求，重启通信选项退出仅监听模式但本身也不响应
+func TestListenOnlyMode(t *testing.T) {
+	s, m := startDiagnosticsSlave(t)
+	if err := m.ForceListenOnlyMode(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ReadHoldingRegisterValues(1, 0, 1); err == nil {
+		t.Fatal("read in listen only mode: got a response")
+	}
+	if !s.Handler.ListenOnly() {
+		t.Fatal("slave is not in listen only mode")
+	}
+	if counters := s.Handler.Counters(); counters.ServerNoResponseCount != 2 {
+		t.Fatalf("server no response count = %v, want 2", counters.ServerNoResponseCount)
+	}
+
+	if err := m.RestartCommunicationsOption(1, false); err == nil {
+		t.Fatal("restart communications in listen only mode: got a response")
+	}
+	if s.Handler.ListenOnly() {
+		t.Fatal("slave is still in listen only mode after restart")
+	}
+	if _, err := m.ReadHoldingRegisterValues(1, 0, 1); err != nil {
+		t.Fatalf("read after restart: %v", err)
+	}
+	// 重启通信选项清除计数器，之后计入重启请求本身和一次读取
+	if count, err := m.ReturnServerMessageCount(1); err != nil || count != 2 {
+		t.Fatalf("server message count after restart = %v, %v, want 2", count, err)
+	}
+}
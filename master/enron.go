@@ -0,0 +1,188 @@
+package master
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// SetEnronLayout 设置设备的 Enron/Daniel 寄存器布局，layout 为 nil 时恢复为默认布局
+func (c *ModbusMaster) SetEnronLayout(slaveId byte, layout *common.EnronLayout) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if layout == nil {
+		delete(c.enronLayouts, slaveId)
+		return
+	}
+	if c.enronLayouts == nil {
+		c.enronLayouts = make(map[byte]*common.EnronLayout)
+	}
+	c.enronLayouts[slaveId] = layout
+}
+
+// EnronLayout 返回设备的 Enron/Daniel 寄存器布局，未设置时为 common.DefaultEnronLayout
+func (c *ModbusMaster) EnronLayout(slaveId byte) *common.EnronLayout {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if layout, ok := c.enronLayouts[slaveId]; ok {
+		return layout
+	}
+	return common.DefaultEnronLayout()
+}
+
+// ReadEnronRegisters 按设备的 Enron 布局读取保持寄存器 (功能码 0x03)，
+// 32 位区间内 quantity 为 32 位值的个数，16 位区间内每个值为 16 位
+func (c *ModbusMaster) ReadEnronRegisters(slaveId byte, address uint16, quantity uint16) (values []uint32, err error) {
+	layout := c.EnronLayout(slaveId)
+	width, err := layout.Width(address, quantity)
+	if err != nil {
+		return
+	}
+	if err = checkEnronQuantity(quantity, width, common.MaxEnronReadRegisters, MaxReadRegisters); err != nil {
+		return
+	}
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadHoldingRegisters}
+	request.LoadData(address, quantity)
+	response, err := c.send(slaveId, request)
+	if err != nil {
+		return
+	}
+	count := int(response.Data[0])
+	length := len(response.Data) - 1
+	if count != length {
+		err = fmt.Errorf("modbus: response data size '%v' does not match count '%v'", length, count)
+		return
+	}
+	if count != int(quantity)*width {
+		err = fmt.Errorf("modbus: response byte count '%v' does not match expected '%v'", count, int(quantity)*width)
+		return
+	}
+	if width == 4 {
+		return layout.DecodeValues(response.Data[1:])
+	}
+	values = make([]uint32, quantity)
+	for i := range values {
+		values[i] = uint32(binary.BigEndian.Uint16(response.Data[1+i*2:]))
+	}
+	return
+}
+
+// ReadEnronFloat32s 读取 32 位区间内的 quantity 个浮点数 (功能码 0x03)
+func (c *ModbusMaster) ReadEnronFloat32s(slaveId byte, address uint16, quantity uint16) ([]float32, error) {
+	if !c.EnronLayout(slaveId).Is32Bit(address) {
+		return nil, fmt.Errorf("modbus: address '%v' is not in a 32-bit range", address)
+	}
+	values, err := c.ReadEnronRegisters(slaveId, address, quantity)
+	if err != nil {
+		return nil, err
+	}
+	floats := make([]float32, len(values))
+	for i, value := range values {
+		floats[i] = math.Float32frombits(value)
+	}
+	return floats, nil
+}
+
+// WriteEnronSingleRegister 按设备的 Enron 布局写入单个保持寄存器 (功能码 0x06)，
+// 32 位区间内写入 4 字节的值，16 位区间内 value 不能超过 0xFFFF
+func (c *ModbusMaster) WriteEnronSingleRegister(slaveId byte, address uint16, value uint32) (err error) {
+	layout := c.EnronLayout(slaveId)
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteSingleRegister}
+	request.LoadData(address)
+	if layout.Is32Bit(address) {
+		request.Data = layout.AppendValues(request.Data, []uint32{value})
+	} else {
+		if value > 0xFFFF {
+			err = fmt.Errorf("modbus: value '%v' of 16-bit register '%v' exceeds '%v'", value, address, 0xFFFF)
+			return
+		}
+		request.Data = binary.BigEndian.AppendUint16(request.Data, uint16(value))
+	}
+	response, err := c.send(slaveId, request)
+	if err != nil {
+		return
+	}
+	if !bytes.Equal(response.Data, request.Data) {
+		err = fmt.Errorf("modbus: response data '%x' does not match request '%x'", response.Data, request.Data)
+		return
+	}
+	return
+}
+
+// WriteEnronMultipleRegisters 按设备的 Enron 布局写入多个保持寄存器 (功能码 0x10)，
+// 32 位区间内每个值为 4 字节，16 位区间内每个值不能超过 0xFFFF
+func (c *ModbusMaster) WriteEnronMultipleRegisters(slaveId byte, address uint16, values []uint32) (err error) {
+	if len(values) > 0xFFFF {
+		err = fmt.Errorf("modbus: quantity '%v' is out of range [1, %v]", len(values), MaxWriteRegisters)
+		return
+	}
+	quantity := uint16(len(values))
+	layout := c.EnronLayout(slaveId)
+	width, err := layout.Width(address, quantity)
+	if err != nil {
+		return
+	}
+	if err = checkEnronQuantity(quantity, width, common.MaxEnronWriteRegisters, MaxWriteRegisters); err != nil {
+		return
+	}
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteMultipleRegisters}
+	request.LoadData(address, quantity).Append(byte(int(quantity) * width))
+	if width == 4 {
+		request.Data = layout.AppendValues(request.Data, values)
+	} else {
+		for _, value := range values {
+			if value > 0xFFFF {
+				err = fmt.Errorf("modbus: value '%v' of 16-bit register exceeds '%v'", value, 0xFFFF)
+				return
+			}
+			request.Data = binary.BigEndian.AppendUint16(request.Data, uint16(value))
+		}
+	}
+	response, err := c.send(slaveId, request)
+	if err != nil {
+		return
+	}
+	// Fixed response length
+	if len(response.Data) != 4 {
+		err = fmt.Errorf("modbus: response data size '%v' does not match expected '%v'", len(response.Data), 4)
+		return
+	}
+	respValue := binary.BigEndian.Uint16(response.Data)
+	if address != respValue {
+		err = fmt.Errorf("modbus: response address '%v' does not match request '%v'", respValue, address)
+		return
+	}
+	respValue = binary.BigEndian.Uint16(response.Data[2:])
+	if quantity != respValue {
+		err = fmt.Errorf("modbus: response quantity '%v' does not match request '%v'", respValue, quantity)
+		return
+	}
+	return
+}
+
+// WriteEnronFloat32s 写入浮点数到 32 位区间 (功能码 0x10)
+func (c *ModbusMaster) WriteEnronFloat32s(slaveId byte, address uint16, values []float32) error {
+	if !c.EnronLayout(slaveId).Is32Bit(address) {
+		return fmt.Errorf("modbus: address '%v' is not in a 32-bit range", address)
+	}
+	bits := make([]uint32, len(values))
+	for i, value := range values {
+		bits[i] = math.Float32bits(value)
+	}
+	return c.WriteEnronMultipleRegisters(slaveId, address, bits)
+}
+
+// checkEnronQuantity 按寄存器宽度检查单次请求的数量
+func checkEnronQuantity(quantity uint16, width int, max32 uint16, max16 uint16) error {
+	limit := max16
+	if width == 4 {
+		limit = max32
+	}
+	if quantity < 1 || quantity > limit {
+		return fmt.Errorf("modbus: quantity '%v' is out of range [1, %v]", quantity, limit)
+	}
+	return nil
+}
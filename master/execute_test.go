@@ -0,0 +1,62 @@
+package master_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// byteCountLength 功能码 65 的响应为字节数及随后的数据，其它功能码使用内置的计算
+func byteCountLength(requestData []byte, responseData []byte) int {
+	if requestData[1] != 65 {
+		return 0
+	}
+	if len(responseData) < 3 {
+		return 3
+	}
+	return 3 + int(responseData[2]) + 2
+}
+
+// TestExecuteRTUResponseLength 响应长度函数按主站设置，未设置的主站读取自定义功能码时返回错误
+func TestExecuteRTUResponseLength(t *testing.T) {
+	s := slave.NewModbusRTUOverTCPSlave(1, &slave.DeviceInfo{}, slave.NewMemoryDataStore())
+	err := s.RegisterFunction(65, func(ctx context.Context, request *common.ProtocolDataUnit) (*common.ProtocolDataUnit, error) {
+		return &common.ProtocolDataUnit{FunctionCode: 65, Data: []byte{3, 0x0A, 0x0B, 0x0C}}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := startNetServer(t, &s.ModbusDevice)
+
+	configured := master.NewModbusRTUOverTCPMasterWithAddress(address)
+	if err = configured.SetResponseLength(byteCountLength); err != nil {
+		t.Fatal(err)
+	}
+	unconfigured := master.NewModbusRTUOverTCPMasterWithAddress(address)
+
+	request := &common.ProtocolDataUnit{FunctionCode: 65}
+	if _, err = unconfigured.Execute(1, request); err == nil {
+		t.Fatal("execute without response length: got nil error")
+	}
+	for i := 0; i < 2; i++ {
+		response, err := configured.Execute(1, request)
+		if err != nil {
+			t.Fatalf("execute %v: %v", i, err)
+		}
+		if want := []byte{3, 0x0A, 0x0B, 0x0C}; !bytes.Equal(response.Data, want) {
+			t.Fatalf("execute %v data = %v, want %v", i, response.Data, want)
+		}
+	}
+	// 返回 0 的功能码使用内置的计算
+	if _, err = configured.ReadHoldingRegisterValues(1, 0, 2); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	if err = master.NewModbusTCPMasterWithAddress(address).SetResponseLength(byteCountLength); err == nil {
+		t.Fatal("set response length on MBAP master: got nil error")
+	}
+}
@@ -0,0 +1,66 @@
+package master_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// TestReadFIFOQueue 读取不清空队列，队列中超过 31 个数据时返回非法数据值异常
+func TestReadFIFOQueue(t *testing.T) {
+	store := slave.NewMemoryDataStore()
+	queue := store.AddFIFOQueue(0x04DE)
+	m := master.NewModbusRTUOverTCPMasterWithAddress(startNetServer(t, &slave.NewModbusRTUOverTCPSlave(1, &slave.DeviceInfo{}, store).ModbusDevice))
+
+	read := func() []uint16 {
+		t.Helper()
+		registers, err := m.ReadFIFOQueue(1, 0x04DE)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return common.RegisterValues(registers)
+	}
+
+	if values := read(); len(values) != 0 {
+		t.Fatalf("empty queue = %v", values)
+	}
+	if err := queue.Push(0x01B8, 0x1284); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if values := read(); !slices.Equal(values, []uint16{0x01B8, 0x1284}) {
+			t.Fatalf("read %v = %#x", i, values)
+		}
+	}
+	if queue.Len() != 2 {
+		t.Fatalf("queue length after reads = %v, want 2", queue.Len())
+	}
+
+	for queue.Len() < slave.FIFOQueueMaxCount {
+		if err := queue.Push(uint16(queue.Len())); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if values := read(); len(values) != slave.FIFOQueueMaxCount {
+		t.Fatalf("full queue length = %v, want %v", len(values), slave.FIFOQueueMaxCount)
+	}
+	if err := queue.Push(0); !errors.Is(err, slave.ErrFIFOQueueFull) {
+		t.Fatalf("push beyond default capacity: got %v, want %v", err, slave.ErrFIFOQueueFull)
+	}
+
+	queue.SetCapacity(slave.FIFOQueueMaxCount + 1)
+	if err := queue.Push(0); err != nil {
+		t.Fatal(err)
+	}
+	var exception *common.Error
+	if _, err := m.ReadFIFOQueue(1, 0x04DE); !errors.As(err, &exception) || exception.ExceptionCode != common.ExceptionCodeIllegalDataValue {
+		t.Fatalf("queue with %v values: got %v, want illegal data value exception", queue.Len(), err)
+	}
+	if _, err := m.ReadFIFOQueue(1, 0x0001); !errors.As(err, &exception) || exception.ExceptionCode != common.ExceptionCodeIllegalDataAddress {
+		t.Fatalf("unknown pointer address: got %v, want illegal data address exception", err)
+	}
+}
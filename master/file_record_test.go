@@ -0,0 +1,103 @@
+package master_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+func TestFileRecord(t *testing.T) {
+	store := slave.NewMemoryDataStore()
+	store.CreateFile(1, 100)
+	store.CreateFile(2, 10)
+	m := master.NewModbusTCPMasterWithAddress(startTCPSlave(t, 1, store))
+
+	err := m.WriteFileRecord(1, []*common.FileRecord{
+		{FileNumber: 1, RecordNumber: 0, Registers: common.NewRegistersFromUInt16s([]uint16{1, 2, 3})},
+		{FileNumber: 2, RecordNumber: 8, Registers: common.NewRegistersFromUInt16s([]uint16{9, 8})},
+		{FileNumber: 1, RecordNumber: 99, Registers: common.NewRegistersFromUInt16s([]uint16{7})},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := []common.FileRecordRequest{
+		{FileNumber: 1, RecordNumber: 1, RecordLength: 2},
+		{FileNumber: 2, RecordNumber: 7, RecordLength: 3},
+		{FileNumber: 1, RecordNumber: 99, RecordLength: 1},
+	}
+	want := [][]uint16{{2, 3}, {0, 9, 8}, {7}}
+	records, err := m.ReadFileRecord(1, requests)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(want) {
+		t.Fatalf("records = %v, want %v", len(records), len(want))
+	}
+	for i, record := range records {
+		if record.FileNumber != requests[i].FileNumber || record.RecordNumber != requests[i].RecordNumber || !slices.Equal(common.RegisterValues(record.Registers), want[i]) {
+			t.Fatalf("record %v = %v %v %v, want %v", i, record.FileNumber, record.RecordNumber, common.RegisterValues(record.Registers), want[i])
+		}
+	}
+}
+
+// TestWriteFileRecordInvalidSubRequest 任一子请求无效时返回异常，且不写入任何文件
+func TestWriteFileRecordInvalidSubRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		invalid *common.FileRecord
+	}{
+		{name: "record past end of file", invalid: &common.FileRecord{FileNumber: 2, RecordNumber: 9, Registers: common.NewRegistersFromUInt16s([]uint16{1, 2})}},
+		{name: "missing file", invalid: &common.FileRecord{FileNumber: 3, RecordNumber: 0, Registers: common.NewRegistersFromUInt16s([]uint16{1})}},
+	}
+	store := slave.NewMemoryDataStore()
+	store.CreateFile(1, 10)
+	store.CreateFile(2, 10)
+	m := master.NewModbusTCPMasterWithAddress(startTCPSlave(t, 1, store))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.WriteFileRecord(1, []*common.FileRecord{
+				{FileNumber: 1, RecordNumber: 0, Registers: common.NewRegistersFromUInt16s([]uint16{5, 5})},
+				{FileNumber: 2, RecordNumber: 0, Registers: common.NewRegistersFromUInt16s([]uint16{6})},
+				tt.invalid,
+			})
+			var exception *common.Error
+			if !errors.As(err, &exception) || exception.ExceptionCode != common.ExceptionCodeIllegalDataAddress {
+				t.Fatalf("got %v, want illegal data address exception", err)
+			}
+			for _, fileNumber := range []uint16{1, 2} {
+				values, err := store.ReadFileRecords(fileNumber, 0, 10)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !slices.Equal(values, make([]uint16, 10)) {
+					t.Fatalf("file %v = %v, want unchanged", fileNumber, values)
+				}
+			}
+		})
+	}
+}
+
+// TestFileRecordReferenceType 参考类型不为 6 时返回异常
+func TestFileRecordReferenceType(t *testing.T) {
+	store := slave.NewMemoryDataStore()
+	store.CreateFile(1, 10)
+	m := master.NewModbusTCPMasterWithAddress(startTCPSlave(t, 1, store))
+	requests := []*common.ProtocolDataUnit{
+		{FunctionCode: common.FuncCodeReadFileRecord, Data: []byte{0x07, 0x05, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01}},
+		{FunctionCode: common.FuncCodeWriteFileRecord, Data: []byte{0x09, 0x05, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x12, 0x34}},
+	}
+	for _, request := range requests {
+		var exception *common.Error
+		if _, err := m.Execute(1, request); !errors.As(err, &exception) || exception.ExceptionCode != common.ExceptionCodeIllegalDataAddress {
+			t.Fatalf("function code %v: got %v, want illegal data address exception", request.FunctionCode, err)
+		}
+	}
+	if values, _ := store.ReadFileRecords(1, 0, 1); values[0] != 0 {
+		t.Fatalf("record = %#x, want unchanged", values[0])
+	}
+}
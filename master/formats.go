@@ -0,0 +1,110 @@
+package master
+
+import (
+	"time"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// 以下方法读取时 functionCode 为 FuncCodeReadHoldingRegisters 或 FuncCodeReadInputRegisters，写入保持寄存器，
+// 编解码规则见 common 中对应的 Decode/Encode 函数
+
+// ReadString 读取 quantity 个寄存器中的字符串
+func (c *ModbusMaster) ReadString(slaveId byte, functionCode byte, address uint16, quantity uint16, swapBytes bool) (string, error) {
+	registers, err := c.readRegisterBlock(slaveId, functionCode, address, quantity)
+	if err != nil {
+		return "", err
+	}
+	return common.DecodeString(registers, swapBytes), nil
+}
+
+// WriteString 将字符串写入 quantity 个寄存器，不足部分以 0x00 填充
+func (c *ModbusMaster) WriteString(slaveId byte, address uint16, quantity uint16, value string, swapBytes bool) error {
+	registers, err := common.EncodeString(value, int(quantity), swapBytes)
+	if err != nil {
+		return err
+	}
+	return c.WriteMultipleRegisters(slaveId, address, registers)
+}
+
+// ReadBCD 读取 quantity (1-4) 个寄存器中的压缩 BCD 码
+func (c *ModbusMaster) ReadBCD(slaveId byte, functionCode byte, address uint16, quantity uint16) (uint64, error) {
+	registers, err := c.readRegisterBlock(slaveId, functionCode, address, quantity)
+	if err != nil {
+		return 0, err
+	}
+	return common.DecodeBCD(registers)
+}
+
+// WriteBCD 将数值以压缩 BCD 码写入 quantity (1-4) 个寄存器
+func (c *ModbusMaster) WriteBCD(slaveId byte, address uint16, quantity uint16, value uint64) error {
+	registers, err := common.EncodeBCD(value, int(quantity))
+	if err != nil {
+		return err
+	}
+	return c.WriteMultipleRegisters(slaveId, address, registers)
+}
+
+// ReadSignedMagnitude 读取 quantity (1-4) 个寄存器中符号-幅值表示的整数
+func (c *ModbusMaster) ReadSignedMagnitude(slaveId byte, functionCode byte, address uint16, quantity uint16, order common.ByteOrder) (int64, error) {
+	registers, err := c.readRegisterBlock(slaveId, functionCode, address, quantity)
+	if err != nil {
+		return 0, err
+	}
+	return common.DecodeSignedMagnitude(registers, order)
+}
+
+// WriteSignedMagnitude 将整数以符号-幅值表示写入 quantity (1-4) 个寄存器
+func (c *ModbusMaster) WriteSignedMagnitude(slaveId byte, address uint16, quantity uint16, value int64, order common.ByteOrder) error {
+	registers, err := common.EncodeSignedMagnitude(value, int(quantity), order)
+	if err != nil {
+		return err
+	}
+	return c.WriteMultipleRegisters(slaveId, address, registers)
+}
+
+// ReadBitField 读取 quantity 个寄存器并展开为位向量，每个寄存器 16 位，bit 0 为最低位
+func (c *ModbusMaster) ReadBitField(slaveId byte, functionCode byte, address uint16, quantity uint16) (*common.BitVector, error) {
+	registers, err := c.readRegisterBlock(slaveId, functionCode, address, quantity)
+	if err != nil {
+		return nil, err
+	}
+	return common.RegistersToBitVector(registers), nil
+}
+
+// WriteBitField 将位向量按每 16 位打包写入寄存器
+func (c *ModbusMaster) WriteBitField(slaveId byte, address uint16, bits *common.BitVector) error {
+	return c.WriteMultipleRegisters(slaveId, address, common.BitVectorToRegisters(bits))
+}
+
+// ReadUnixTime 读取 Unix 时间戳（秒），quantity 为 2 (32 位) 或 4 (64 位)
+func (c *ModbusMaster) ReadUnixTime(slaveId byte, functionCode byte, address uint16, quantity uint16, order common.ByteOrder) (time.Time, error) {
+	registers, err := c.readRegisterBlock(slaveId, functionCode, address, quantity)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return common.DecodeUnixTime(registers, order)
+}
+
+// WriteUnixTime 写入 Unix 时间戳（秒），quantity 为 2 (32 位) 或 4 (64 位)
+func (c *ModbusMaster) WriteUnixTime(slaveId byte, address uint16, quantity uint16, t time.Time, order common.ByteOrder) error {
+	registers, err := common.EncodeUnixTime(t, int(quantity), order)
+	if err != nil {
+		return err
+	}
+	return c.WriteMultipleRegisters(slaveId, address, registers)
+}
+
+// ReadDateTime 读取按 年 月 日 时 分 秒 存放的 6 个寄存器，loc 为设备所在时区
+func (c *ModbusMaster) ReadDateTime(slaveId byte, functionCode byte, address uint16, loc *time.Location) (time.Time, error) {
+	registers, err := c.readRegisterBlock(slaveId, functionCode, address, common.DateTimeRegisterCount)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return common.DecodeDateTime(registers, loc)
+}
+
+// WriteDateTime 将时间按 年 月 日 时 分 秒 写入 6 个寄存器，loc 为设备所在时区
+func (c *ModbusMaster) WriteDateTime(slaveId byte, address uint16, t time.Time, loc *time.Location) error {
+	return c.WriteMultipleRegisters(slaveId, address, common.EncodeDateTime(t, loc))
+}
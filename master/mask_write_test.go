@@ -0,0 +1,40 @@
+package master_test
+
+import (
+	"testing"
+
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+func TestMaskWriteRegister(t *testing.T) {
+	tests := []struct {
+		name    string
+		current uint16
+		andMask uint16
+		orMask  uint16
+		want    uint16
+	}{
+		{name: "specification example", current: 0x12, andMask: 0xF2, orMask: 0x25, want: 0x17},
+		{name: "and all ones keeps value", current: 0x1234, andMask: 0xFFFF, orMask: 0xFFFF, want: 0x1234},
+		{name: "and zero replaces value", current: 0x1234, andMask: 0x0000, orMask: 0xABCD, want: 0xABCD},
+	}
+	store := slave.NewMemoryDataStore()
+	m := master.NewModbusTCPMasterWithAddress(startTCPSlave(t, 1, store))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.Write(slave.PointTypeHoldingRegister, 0, tt.current)
+			if got := store.MaskWrite(slave.PointTypeHoldingRegister, 0, tt.andMask, tt.orMask); got != tt.want {
+				t.Fatalf("store mask write = %#x, want %#x", got, tt.want)
+			}
+
+			store.Write(slave.PointTypeHoldingRegister, 1, tt.current)
+			if err := m.MaskWriteRegister(1, 1, tt.andMask, tt.orMask); err != nil {
+				t.Fatal(err)
+			}
+			if got := store.Read(slave.PointTypeHoldingRegister, 1); got != tt.want {
+				t.Fatalf("register after master mask write = %#x, want %#x", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,19 +1,33 @@
 package master
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
+	"sync"
+	"time"
+
 	"github.com/veryinf/modbus-kit/common"
 )
 
+// DefaultTurnaroundDelay 广播请求发送后的默认等待时间
+const DefaultTurnaroundDelay = 100 * time.Millisecond
+
 type ModbusMaster struct {
 	message   common.Message
 	transport common.Transport
+	// TurnaroundDelay 广播请求和强制仅监听模式请求发送后的等待时间，留给从站处理不返回响应的请求，期间不应发送新的请求
+	TurnaroundDelay time.Duration
+
+	mu              sync.Mutex
+	blockLimits     map[byte]BlockLimits
+	enronLayouts    map[byte]*common.EnronLayout
+	serverIDLengths map[byte]int
 }
 
 // NewModbusMaster 创建一个新的 ModbusMaster 对象
 func NewModbusMaster(message common.Message, transport common.Transport) *ModbusMaster {
-	return &ModbusMaster{message: message, transport: transport}
+	return &ModbusMaster{message: message, transport: transport, TurnaroundDelay: DefaultTurnaroundDelay}
 }
 
 // ReadCoils
@@ -97,23 +111,11 @@ func (c *ModbusMaster) ReadDiscreteInputs(slaveId byte, address uint16, quantity
 //	Byte count            : 1 byte
 //	Register value        : Nx2 bytes
 func (c *ModbusMaster) ReadHoldingRegisters(slaveId byte, address uint16, quantity uint8) (registers []*common.Register, err error) {
-	if quantity < 1 || quantity > 125 {
-		err = fmt.Errorf("modbus: quantity '%v' is out of range [1, 125]", quantity)
-		return
-	}
-	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadHoldingRegisters}
-	request.LoadData(address, uint16(quantity))
-	response, err := c.send(slaveId, request)
+	data, err := c.readRegisters(slaveId, common.FuncCodeReadHoldingRegisters, address, uint16(quantity))
 	if err != nil {
 		return
 	}
-	count := int(response.Data[0])
-	length := len(response.Data) - 1
-	if count != length {
-		err = fmt.Errorf("modbus: response data size '%v' does not match count '%v'", length, count)
-		return
-	}
-	registers = common.NewRegisters(response.Data[1:])
+	registers = common.NewRegisters(data)
 	return
 }
 
@@ -130,23 +132,11 @@ func (c *ModbusMaster) ReadHoldingRegisters(slaveId byte, address uint16, quanti
 //	Byte count            : 1 byte
 //	Input registers       : N bytes
 func (c *ModbusMaster) ReadInputRegisters(slaveId byte, address uint16, quantity uint8) (registers []*common.Register, err error) {
-	if quantity < 1 || quantity > 125 {
-		err = fmt.Errorf("modbus: quantity '%v' is out of range [1, 125]", quantity)
-		return
-	}
-	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadInputRegisters}
-	request.LoadData(address, uint16(quantity))
-	response, err := c.send(slaveId, request)
+	data, err := c.readRegisters(slaveId, common.FuncCodeReadInputRegisters, address, uint16(quantity))
 	if err != nil {
 		return
 	}
-	count := int(response.Data[0])
-	length := len(response.Data) - 1
-	if count != length {
-		err = fmt.Errorf("modbus: response data size '%v' does not match count '%v'", length, count)
-		return
-	}
-	registers = common.NewRegisters(response.Data[1:])
+	registers = common.NewRegisters(data)
 	return
 }
 
@@ -290,21 +280,33 @@ func (c *ModbusMaster) WriteMultipleCoils(slaveId byte, address uint16, values [
 //	Starting address      : 2 bytes
 //	Quantity of registers : 2 bytes
 func (c *ModbusMaster) WriteMultipleRegisters(slaveId byte, address uint16, registers []*common.Register) (err error) {
-	quantity := uint8(len(registers))
-	if quantity < 1 || quantity > 123 {
-		err = fmt.Errorf("modbus: quantity '%v' is out of range [1, 123]", quantity)
-		return
-	}
-	value := *common.RegistersToBytes(registers)
-	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteMultipleRegisters}
-	request.LoadData(address, uint16(quantity)).Append(byte(len(value))).Append(value...)
+	return c.WriteMultipleRegisterValues(slaveId, address, common.RegisterValues(registers))
+}
+
+// MaskWriteRegister
+// Request:
+//
+//	Function code         : 1 byte (0x16)
+//	Reference address     : 2 bytes
+//	AND-mask              : 2 bytes
+//	OR-mask               : 2 bytes
+//
+// Response:
+//
+//	Function code         : 1 byte (0x16)
+//	Reference address     : 2 bytes
+//	AND-mask              : 2 bytes
+//	OR-mask               : 2 bytes
+func (c *ModbusMaster) MaskWriteRegister(slaveId byte, address, andMask, orMask uint16) (err error) {
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeMaskWriteRegister}
+	request.LoadData(address, andMask, orMask)
 	response, err := c.send(slaveId, request)
 	if err != nil {
 		return
 	}
 	// Fixed response length
-	if len(response.Data) != 4 {
-		err = fmt.Errorf("modbus: response data size '%v' does not match expected '%v'", len(response.Data), 4)
+	if len(response.Data) != 6 {
+		err = fmt.Errorf("modbus: response data size '%v' does not match expected '%v'", len(response.Data), 6)
 		return
 	}
 	respValue := binary.BigEndian.Uint16(response.Data)
@@ -313,31 +315,58 @@ func (c *ModbusMaster) WriteMultipleRegisters(slaveId byte, address uint16, regi
 		return
 	}
 	respValue = binary.BigEndian.Uint16(response.Data[2:])
-	if uint16(quantity) != respValue {
-		err = fmt.Errorf("modbus: response quantity '%v' does not match request '%v'", respValue, quantity)
+	if andMask != respValue {
+		err = fmt.Errorf("modbus: response AND-mask '%v' does not match request '%v'", respValue, andMask)
+		return
+	}
+	respValue = binary.BigEndian.Uint16(response.Data[4:])
+	if orMask != respValue {
+		err = fmt.Errorf("modbus: response OR-mask '%v' does not match request '%v'", respValue, orMask)
 		return
 	}
 	return
 }
 
-// ReadDeviceIdentification
+// ReadWriteMultipleRegisters
 // Request:
 //
-//		Function code         : 1 byte (0x2B)
-//		Entity Identifier     : 1 byte (0x0E)
-//		Subfunction code      : 1 byte (0x01 0x02)
-//	 Object Identifier     : 1 byte
+//	Function code         : 1 byte (0x17)
+//	Read starting address : 2 bytes
+//	Quantity to read      : 2 bytes
+//	Write starting address: 2 bytes
+//	Quantity to write     : 2 bytes
+//	Write byte count      : 1 byte
+//	Write registers value : N* bytes
 //
 // Response:
 //
-//	Function code         : 1 byte (0x04)
+//	Function code         : 1 byte (0x17)
 //	Byte count            : 1 byte
-//	Input registers       : N bytes
-func (c *ModbusMaster) ReadDeviceIdentification(slaveId byte) (info *common.DeviceIdentification, err error) {
-	request := &common.ProtocolDataUnit{
-		FunctionCode: common.FuncCodeReadDeviceIdentification,
-		Data:         []byte{0x0E, 0x01, 0x00},
+//	Read registers value  : Nx2 bytes
+func (c *ModbusMaster) ReadWriteMultipleRegisters(slaveId byte, readAddress uint16, readQuantity uint16, writeAddress uint16, registers []*common.Register) (results []*common.Register, err error) {
+	values, err := c.ReadWriteMultipleRegisterValues(slaveId, readAddress, readQuantity, writeAddress, common.RegisterValues(registers))
+	if err != nil {
+		return
 	}
+	results = common.NewRegistersFromUInt16s(values)
+	return
+}
+
+// ReadFIFOQueue
+// Request:
+//
+//	Function code         : 1 byte (0x18)
+//	FIFO pointer address  : 2 bytes
+//
+// Response:
+//
+//	Function code         : 1 byte (0x18)
+//	Byte count            : 2 bytes
+//	FIFO count            : 2 bytes (<=31)
+//	FIFO value register   : Nx2 bytes
+func (c *ModbusMaster) ReadFIFOQueue(slaveId byte, pointerAddress uint16) (registers []*common.Register, err error) {
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadFIFOQueue}
+	request.LoadData(pointerAddress)
 	response, err := c.send(slaveId, request)
 	if err != nil {
 		return
@@ -346,41 +375,388 @@ func (c *ModbusMaster) ReadDeviceIdentification(slaveId byte) (info *common.Devi
 		err = fmt.Errorf("modbus: response data size '%v' is less than expected '%v'", len(response.Data), 4)
 		return
 	}
-	if response.Data[2] != 0x01 || response.Data[3] != 0x00 || response.Data[4] != 0x00 {
-		err = fmt.Errorf("modbus: currently, read device identification only support read 1 request")
+	count := int(binary.BigEndian.Uint16(response.Data))
+	length := len(response.Data) - 2
+	if count != length {
+		err = fmt.Errorf("modbus: response data size '%v' does not match count '%v'", length, count)
+		return
+	}
+	fifoCount := int(binary.BigEndian.Uint16(response.Data[2:]))
+	if fifoCount > 31 {
+		err = fmt.Errorf("modbus: fifo count '%v' is greater than expected '%v'", fifoCount, 31)
+		return
+	}
+	if count != 2+fifoCount*2 {
+		err = fmt.Errorf("modbus: response byte count '%v' does not match fifo count '%v'", count, fifoCount)
+		return
+	}
+	registers = common.NewRegisters(response.Data[4:])
+	return
+}
+
+// ReadFileRecord
+// Request:
+//
+//	Function code         : 1 byte (0x14)
+//	Byte count            : 1 byte (0x07 to 0xF5)
+//	Sub-Req. x, Reference type : 1 byte (0x06)
+//	Sub-Req. x, File number    : 2 bytes
+//	Sub-Req. x, Record number  : 2 bytes
+//	Sub-Req. x, Record length  : 2 bytes
+//	Sub-Req. x+1, ...
+//
+// Response:
+//
+//	Function code         : 1 byte (0x14)
+//	Resp. data length     : 1 byte
+//	Sub-Req. x, File resp. length : 1 byte
+//	Sub-Req. x, Reference type    : 1 byte (0x06)
+//	Sub-Req. x, Record data       : Nx2 bytes
+//	Sub-Req. x+1, ...
+func (c *ModbusMaster) ReadFileRecord(slaveId byte, requests []common.FileRecordRequest) (records []*common.FileRecord, err error) {
+	byteCount := len(requests) * 7
+	if byteCount < 0x07 || byteCount > 0xF5 {
+		err = fmt.Errorf("modbus: quantity of sub-requests '%v' is out of range [1, 35]", len(requests))
+		return
+	}
+	responseLength := 1
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadFileRecord}
+	request.Append(byte(byteCount))
+	for _, sub := range requests {
+		if sub.FileNumber < 1 {
+			err = fmt.Errorf("modbus: file number '%v' must not be zero", sub.FileNumber)
+			return
+		}
+		if sub.RecordNumber > common.FileRecordMaxRecordNumber {
+			err = fmt.Errorf("modbus: record number '%v' is out of range [0, %v]", sub.RecordNumber, common.FileRecordMaxRecordNumber)
+			return
+		}
+		if sub.RecordLength < 1 {
+			err = fmt.Errorf("modbus: record length of file '%v' must not be zero", sub.FileNumber)
+			return
+		}
+		responseLength += 2 + int(sub.RecordLength)*2
+		request.Append(common.FileRecordReferenceType,
+			byte(sub.FileNumber>>8), byte(sub.FileNumber),
+			byte(sub.RecordNumber>>8), byte(sub.RecordNumber),
+			byte(sub.RecordLength>>8), byte(sub.RecordLength))
+	}
+	if responseLength > 0xF6 {
+		err = fmt.Errorf("modbus: response data length '%v' must not greater than '%v'", responseLength-1, 0xF5)
+		return
+	}
+	response, err := c.send(slaveId, request)
+	if err != nil {
+		return
+	}
+	count := int(response.Data[0])
+	length := len(response.Data) - 1
+	if count != length {
+		err = fmt.Errorf("modbus: response data size '%v' does not match count '%v'", length, count)
+		return
+	}
+	records = make([]*common.FileRecord, 0, len(requests))
+	offset := 1
+	for _, sub := range requests {
+		if offset+2 > len(response.Data) {
+			err = fmt.Errorf("modbus: response is missing record data of file '%v'", sub.FileNumber)
+			return
+		}
+		fileLength := int(response.Data[offset])
+		if fileLength != 1+int(sub.RecordLength)*2 || offset+1+fileLength > len(response.Data) {
+			err = fmt.Errorf("modbus: response file length '%v' does not match record length '%v'", fileLength, sub.RecordLength)
+			return
+		}
+		if response.Data[offset+1] != common.FileRecordReferenceType {
+			err = fmt.Errorf("modbus: response reference type '%v' does not match expected '%v'", response.Data[offset+1], common.FileRecordReferenceType)
+			return
+		}
+		records = append(records, &common.FileRecord{
+			FileNumber:   sub.FileNumber,
+			RecordNumber: sub.RecordNumber,
+			Registers:    common.NewRegisters(response.Data[offset+2 : offset+1+fileLength]),
+		})
+		offset += 1 + fileLength
+	}
+	return
+}
+
+// WriteFileRecord
+// Request:
+//
+//	Function code         : 1 byte (0x15)
+//	Request data length   : 1 byte (0x09 to 0xFB)
+//	Sub-Req. x, Reference type : 1 byte (0x06)
+//	Sub-Req. x, File number    : 2 bytes
+//	Sub-Req. x, Record number  : 2 bytes
+//	Sub-Req. x, Record length  : 2 bytes
+//	Sub-Req. x, Record data    : Nx2 bytes
+//	Sub-Req. x+1, ...
+//
+// Response:
+//
+//	Echo of the request
+func (c *ModbusMaster) WriteFileRecord(slaveId byte, records []*common.FileRecord) (err error) {
+	if len(records) < 1 {
+		err = fmt.Errorf("modbus: quantity of sub-requests must not be zero")
+		return
+	}
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteFileRecord}
+	request.Append(0)
+	for _, record := range records {
+		if record.FileNumber < 1 {
+			err = fmt.Errorf("modbus: file number '%v' must not be zero", record.FileNumber)
+			return
+		}
+		quantity := uint16(len(record.Registers))
+		if quantity < 1 {
+			err = fmt.Errorf("modbus: record length of file '%v' must not be zero", record.FileNumber)
+			return
+		}
+		if int(record.RecordNumber)+int(quantity)-1 > common.FileRecordMaxRecordNumber {
+			err = fmt.Errorf("modbus: record number '%v' is out of range [0, %v]", int(record.RecordNumber)+int(quantity)-1, common.FileRecordMaxRecordNumber)
+			return
+		}
+		request.Append(common.FileRecordReferenceType,
+			byte(record.FileNumber>>8), byte(record.FileNumber),
+			byte(record.RecordNumber>>8), byte(record.RecordNumber),
+			byte(quantity>>8), byte(quantity))
+		request.Append(*common.RegistersToBytes(record.Registers)...)
+	}
+	byteCount := len(request.Data) - 1
+	if byteCount < 0x09 || byteCount > 0xFB {
+		err = fmt.Errorf("modbus: request data length '%v' is out of range [%v, %v]", byteCount, 0x09, 0xFB)
+		return
+	}
+	request.Data[0] = byte(byteCount)
+	response, err := c.send(slaveId, request)
+	if err != nil {
+		return
+	}
+	if !bytes.Equal(response.Data, request.Data) {
+		err = fmt.Errorf("modbus: response data does not match request")
+		return
+	}
+	return
+}
+
+// ReportServerID
+// Request:
+//
+//	Function code         : 1 byte (0x11)
+//
+// Response:
+//
+//	Function code         : 1 byte (0x11)
+//	Byte count            : 1 byte
+//	Server ID             : device specific
+//	Run indicator status  : 1 byte (0x00 = OFF, 0xFF = ON)
+//	Additional data       : N bytes
+//
+// 服务器 ID 的长度由设备自行定义，无法从响应中确定。已通过 SetServerIDLength 设置长度时按该长度解析，
+// 否则返回的 ServerID 只包含原始数据 Data，ID、RunIndicator 和 AdditionalData 为空，可对 Data 调用 common.DecodeServerID 解析
+func (c *ModbusMaster) ReportServerID(slaveId byte) (serverID *common.ServerID, err error) {
+	data, err := c.reportServerID(slaveId)
+	if err != nil {
+		return
+	}
+	if idLength, ok := c.serverIDLength(slaveId); ok {
+		return common.DecodeServerID(data, idLength)
+	}
+	return &common.ServerID{Data: data}, nil
+}
+
+// SetServerIDLength 设置设备服务器 ID 的字节数，ReportServerID 按该长度解析响应，idLength 小于 0 时删除设置
+func (c *ModbusMaster) SetServerIDLength(slaveId byte, idLength int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if idLength < 0 {
+		delete(c.serverIDLengths, slaveId)
+		return
+	}
+	if c.serverIDLengths == nil {
+		c.serverIDLengths = make(map[byte]int)
+	}
+	c.serverIDLengths[slaveId] = idLength
+}
+
+// serverIDLength 返回设备服务器 ID 的字节数，未设置时 ok 为 false
+func (c *ModbusMaster) serverIDLength(slaveId byte) (idLength int, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	idLength, ok = c.serverIDLengths[slaveId]
+	return
+}
+
+// ReportServerIDWithLength 读取从站服务器 ID (功能码 0x11)，按 idLength 字节的服务器 ID 解析响应
+func (c *ModbusMaster) ReportServerIDWithLength(slaveId byte, idLength int) (serverID *common.ServerID, err error) {
+	data, err := c.reportServerID(slaveId)
+	if err != nil {
+		return
+	}
+	return common.DecodeServerID(data, idLength)
+}
+
+// reportServerID 发送 Report Server ID 请求，返回除字节数外的响应数据
+func (c *ModbusMaster) reportServerID(slaveId byte) (data []byte, err error) {
+	request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeReportServerID}
+	response, err := c.send(slaveId, request)
+	if err != nil {
+		return
+	}
+	if len(response.Data) < 1 {
+		err = fmt.Errorf("modbus: response data size '%v' is less than expected '%v'", len(response.Data), 1)
+		return
+	}
+	count := int(response.Data[0])
+	length := len(response.Data) - 1
+	if count != length {
+		err = fmt.Errorf("modbus: response data size '%v' does not match count '%v'", length, count)
+		return
+	}
+	data = append([]byte(nil), response.Data[1:]...)
+	return
+}
+
+// ReadDeviceIdentification 读取基本设备标识 (读取码 0x01)
+func (c *ModbusMaster) ReadDeviceIdentification(slaveId byte) (info *common.DeviceIdentification, err error) {
+	return c.ReadDeviceIdentificationStream(slaveId, common.ReadDeviceIDCodeBasic)
+}
+
+// ReadDeviceIdentificationStream 按读取码 (0x01 基本, 0x02 常规, 0x03 扩展) 流式读取设备标识，
+// 从站返回后续标识时按下一对象 ID 继续读取，直至读取完毕
+// Request:
+//
+//	Function code         : 1 byte (0x2B)
+//	MEI type              : 1 byte (0x0E)
+//	Read device ID code   : 1 byte (0x01 0x02 0x03 0x04)
+//	Object id             : 1 byte
+//
+// Response:
+//
+//	Function code         : 1 byte (0x2B)
+//	MEI type              : 1 byte (0x0E)
+//	Read device ID code   : 1 byte
+//	Conformity level      : 1 byte
+//	More follows          : 1 byte (0x00 0xFF)
+//	Next object id        : 1 byte
+//	Number of objects     : 1 byte
+//	Object id             : 1 byte
+//	Object length         : 1 byte
+//	Object value          : N bytes
+//	...
+func (c *ModbusMaster) ReadDeviceIdentificationStream(slaveId byte, readCode byte) (info *common.DeviceIdentification, err error) {
+	if readCode < common.ReadDeviceIDCodeBasic || readCode > common.ReadDeviceIDCodeExtended {
+		err = fmt.Errorf("modbus: read device id code '%v' is out of range [1, 3]", readCode)
 		return
 	}
 	info = &common.DeviceIdentification{}
-	offset := 6
-	for offset < len(response.Data) {
-		objectIdentifier := response.Data[offset]
-		length := response.Data[offset+1]
-		text := response.Data[offset+2 : offset+2+int(length)]
-		valText := string(text)
-		offset += int(length) + 2
-		switch objectIdentifier {
-		case 0x00:
-			info.VendorName = valText
-		case 0x01:
-			info.ProductCode = valText
-		case 0x02:
-			info.ProductVersion = valText
-		case 0x03:
-			info.VendorUrl = valText
-		case 0x04:
-			info.ProductName = valText
-		case 0x05:
-			info.ModelName = valText
-		case 0x06:
-			info.UserApplicationName = valText
+	objectId := common.DeviceObjectVendorName
+	for {
+		result, e := c.readDeviceIdentification(slaveId, readCode, objectId)
+		if e != nil {
+			return nil, e
+		}
+		info.ConformityLevel = result.conformityLevel
+		for _, object := range result.objects {
+			// 忽略保留对象
+			_ = info.SetObject(object.id, object.value)
+		}
+		if !result.moreFollows {
+			return
 		}
+		if result.nextObjectId <= objectId {
+			err = fmt.Errorf("modbus: response next object id '%v' does not advance from '%v'", result.nextObjectId, objectId)
+			return nil, err
+		}
+		objectId = result.nextObjectId
 	}
+}
 
+// ReadDeviceIdentificationObject 读取单个设备标识对象 (读取码 0x04)
+func (c *ModbusMaster) ReadDeviceIdentificationObject(slaveId byte, objectId byte) (value []byte, err error) {
+	result, err := c.readDeviceIdentification(slaveId, common.ReadDeviceIDCodeSpecific, objectId)
+	if err != nil {
+		return
+	}
+	if len(result.objects) != 1 || result.objects[0].id != objectId {
+		err = fmt.Errorf("modbus: response does not contain object '%v'", objectId)
+		return
+	}
+	value = result.objects[0].value
 	return
 }
 
-// 发送请求并检查可能的异常
-func (c *ModbusMaster) send(slaveId byte, request *common.ProtocolDataUnit) (response *common.ProtocolDataUnit, err error) {
+// deviceObject 设备标识对象
+type deviceObject struct {
+	id    byte
+	value []byte
+}
+
+// deviceIdentificationResult 一次读取设备标识请求的响应
+type deviceIdentificationResult struct {
+	conformityLevel byte
+	moreFollows     bool
+	nextObjectId    byte
+	objects         []deviceObject
+}
+
+// readDeviceIdentification 发送一次读取设备标识请求并解析响应
+func (c *ModbusMaster) readDeviceIdentification(slaveId byte, readCode byte, objectId byte) (result *deviceIdentificationResult, err error) {
+	request := &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeReadDeviceIdentification,
+		Data:         []byte{common.MEITypeReadDeviceIdentification, readCode, objectId},
+	}
+	response, err := c.send(slaveId, request)
+	if err != nil {
+		return
+	}
+	if len(response.Data) < 6 {
+		err = fmt.Errorf("modbus: response data size '%v' is less than expected '%v'", len(response.Data), 6)
+		return
+	}
+	if response.Data[0] != common.MEITypeReadDeviceIdentification || response.Data[1] != readCode {
+		err = fmt.Errorf("modbus: response mei type '%v' and read code '%v' do not match request '%v' and '%v'",
+			response.Data[0], response.Data[1], common.MEITypeReadDeviceIdentification, readCode)
+		return
+	}
+	result = &deviceIdentificationResult{
+		conformityLevel: response.Data[2],
+		moreFollows:     response.Data[3] == 0xFF,
+		nextObjectId:    response.Data[4],
+	}
+	count := int(response.Data[5])
+	offset := 6
+	for i := 0; i < count; i++ {
+		if offset+2 > len(response.Data) {
+			err = fmt.Errorf("modbus: response data size '%v' is less than expected '%v'", len(response.Data), offset+2)
+			return nil, err
+		}
+		id := response.Data[offset]
+		length := int(response.Data[offset+1])
+		offset += 2
+		if offset+length > len(response.Data) {
+			err = fmt.Errorf("modbus: response data size '%v' is less than expected '%v'", len(response.Data), offset+length)
+			return nil, err
+		}
+		value := append([]byte(nil), response.Data[offset:offset+length]...)
+		result.objects = append(result.objects, deviceObject{id: id, value: value})
+		offset += length
+	}
+	if offset != len(response.Data) {
+		err = fmt.Errorf("modbus: response data size '%v' does not match expected '%v'", len(response.Data), offset)
+		return nil, err
+	}
+	return
+}
+
+// Execute 发送原始请求 PDU 并返回响应 PDU，可用于自定义功能码。
+// 从站返回异常响应时 err 为 *common.Error；RTU 模式下自定义功能码的响应长度需通过 SetResponseLength 设置计算函数
+func (c *ModbusMaster) Execute(slaveId byte, request *common.ProtocolDataUnit) (response *common.ProtocolDataUnit, err error) {
+	if request.FunctionCode == 0 || request.FunctionCode&0x80 != 0 {
+		err = fmt.Errorf("modbus: function code '%v' is out of range [1, 127]", request.FunctionCode)
+		return
+	}
 	requestData, err := c.message.Encode(slaveId, request)
 	if err != nil {
 		return
@@ -399,6 +775,32 @@ func (c *ModbusMaster) send(slaveId byte, request *common.ProtocolDataUnit) (res
 	// Check correct function code returned (exception)
 	if response.FunctionCode != request.FunctionCode {
 		err = responseError(response)
+		response = nil
+		return
+	}
+	return
+}
+
+// SetResponseLength 设置 RTU 通信层计算响应长度的函数，用于自定义功能码，只影响本主站，应在发送请求前设置。
+// 通信层不是 RTUTransport、RTUOverTCPTransport 或 RTUOverUDPTransport 时返回错误
+func (c *ModbusMaster) SetResponseLength(lengthFunc common.ResponseLengthFunc) error {
+	switch t := c.transport.(type) {
+	case *RTUTransport:
+		t.ResponseLength = lengthFunc
+	case *RTUOverTCPTransport:
+		t.ResponseLength = lengthFunc
+	case *RTUOverUDPTransport:
+		t.ResponseLength = lengthFunc
+	default:
+		return fmt.Errorf("modbus: transport does not read rtu frames")
+	}
+	return nil
+}
+
+// 发送请求并检查可能的异常
+func (c *ModbusMaster) send(slaveId byte, request *common.ProtocolDataUnit) (response *common.ProtocolDataUnit, err error) {
+	response, err = c.Execute(slaveId, request)
+	if err != nil {
 		return
 	}
 	if response.Data == nil || len(response.Data) == 0 {
@@ -409,6 +811,20 @@ func (c *ModbusMaster) send(slaveId byte, request *common.ProtocolDataUnit) (res
 	return
 }
 
+// 发送无需响应的请求，要求通信层实现 common.Transmitter
+func (c *ModbusMaster) transmit(slaveId byte, request *common.ProtocolDataUnit) (err error) {
+	transmitter, ok := c.transport.(common.Transmitter)
+	if !ok {
+		err = fmt.Errorf("modbus: transport does not support sending without response")
+		return
+	}
+	requestData, err := c.message.Encode(slaveId, request)
+	if err != nil {
+		return
+	}
+	return transmitter.Transmit(requestData)
+}
+
 func responseError(response *common.ProtocolDataUnit) error {
 	mbError := &common.Error{FunctionCode: response.FunctionCode}
 	if response.Data != nil && len(response.Data) > 0 {
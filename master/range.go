@@ -0,0 +1,202 @@
+package master
+
+import (
+	"fmt"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// 规范规定的单次请求最大数量
+const (
+	MaxReadBits       = 2000 // 读线圈/离散输入
+	MaxReadRegisters  = 125  // 读保持/输入寄存器
+	MaxWriteBits      = 1968 // 写多个线圈
+	MaxWriteRegisters = 123  // 写多个寄存器
+)
+
+// BlockLimits 设备单次请求的最大数量，为 0 时使用规范规定的最大值
+type BlockLimits struct {
+	ReadBits       uint16
+	ReadRegisters  uint16
+	WriteBits      uint16
+	WriteRegisters uint16
+}
+
+// withDefaults 将未设置的限制替换为规范规定的最大值
+func (l BlockLimits) withDefaults() BlockLimits {
+	if l.ReadBits == 0 {
+		l.ReadBits = MaxReadBits
+	}
+	if l.ReadRegisters == 0 {
+		l.ReadRegisters = MaxReadRegisters
+	}
+	if l.WriteBits == 0 {
+		l.WriteBits = MaxWriteBits
+	}
+	if l.WriteRegisters == 0 {
+		l.WriteRegisters = MaxWriteRegisters
+	}
+	return l
+}
+
+// RangeError 分块读写时某一块请求失败，Done 为失败前已完成的数量，对应的部分结果随错误一同返回
+type RangeError struct {
+	Address  uint16 // 失败块的起始地址
+	Quantity uint16 // 失败块的数量
+	Done     uint16 // 已完成的数量
+	Err      error
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("modbus: block at address '%v' quantity '%v' failed after '%v' done: %v", e.Address, e.Quantity, e.Done, e.Err)
+}
+
+func (e *RangeError) Unwrap() error {
+	return e.Err
+}
+
+// SetBlockLimits 设置设备单次请求的最大数量，超过规范规定的最大值时返回错误
+func (c *ModbusMaster) SetBlockLimits(slaveId byte, limits BlockLimits) error {
+	if limits.ReadBits > MaxReadBits || limits.ReadRegisters > MaxReadRegisters ||
+		limits.WriteBits > MaxWriteBits || limits.WriteRegisters > MaxWriteRegisters {
+		return fmt.Errorf("modbus: block limits '%+v' exceed the protocol maximum", limits)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.blockLimits == nil {
+		c.blockLimits = make(map[byte]BlockLimits)
+	}
+	c.blockLimits[slaveId] = limits
+	return nil
+}
+
+// BlockLimits 返回设备单次请求的最大数量，未设置的项为规范规定的最大值
+func (c *ModbusMaster) BlockLimits(slaveId byte) BlockLimits {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.blockLimits[slaveId].withDefaults()
+}
+
+// ReadCoilsRange 读取任意数量的线圈，按设备的块大小拆分为多次请求
+func (c *ModbusMaster) ReadCoilsRange(slaveId byte, address uint16, quantity uint16) (*common.BitVector, error) {
+	return c.readBitsRange(slaveId, address, quantity, c.ReadCoils)
+}
+
+// ReadDiscreteInputsRange 读取任意数量的离散输入，按设备的块大小拆分为多次请求
+func (c *ModbusMaster) ReadDiscreteInputsRange(slaveId byte, address uint16, quantity uint16) (*common.BitVector, error) {
+	return c.readBitsRange(slaveId, address, quantity, c.ReadDiscreteInputs)
+}
+
+// ReadHoldingRegistersRange 读取任意数量的保持寄存器，按设备的块大小拆分为多次请求
+func (c *ModbusMaster) ReadHoldingRegistersRange(slaveId byte, address uint16, quantity uint16) ([]*common.Register, error) {
+	return registersRange(c.readRegistersRange(slaveId, common.FuncCodeReadHoldingRegisters, address, quantity, 1))
+}
+
+// ReadInputRegistersRange 读取任意数量的输入寄存器，按设备的块大小拆分为多次请求
+func (c *ModbusMaster) ReadInputRegistersRange(slaveId byte, address uint16, quantity uint16) ([]*common.Register, error) {
+	return registersRange(c.readRegistersRange(slaveId, common.FuncCodeReadInputRegisters, address, quantity, 1))
+}
+
+// WriteMultipleCoilsRange 写入任意数量的线圈，按设备的块大小拆分为多次请求
+func (c *ModbusMaster) WriteMultipleCoilsRange(slaveId byte, address uint16, values []bool) error {
+	if err := checkRange(address, len(values)); err != nil {
+		return err
+	}
+	limit := int(c.BlockLimits(slaveId).WriteBits)
+	for done := 0; done < len(values); done += limit {
+		block := values[done:min(done+limit, len(values))]
+		blockAddress := address + uint16(done)
+		if err := c.WriteMultipleCoils(slaveId, blockAddress, block); err != nil {
+			return &RangeError{Address: blockAddress, Quantity: uint16(len(block)), Done: uint16(done), Err: err}
+		}
+	}
+	return nil
+}
+
+// WriteMultipleRegistersRange 写入任意数量的寄存器，按设备的块大小拆分为多次请求
+func (c *ModbusMaster) WriteMultipleRegistersRange(slaveId byte, address uint16, registers []*common.Register) error {
+	return c.writeRegistersRange(slaveId, address, common.RegisterValues(registers), 1)
+}
+
+// readBitsRange 分块读取位数据，失败时返回已读取部分组成的位向量
+func (c *ModbusMaster) readBitsRange(slaveId byte, address uint16, quantity uint16,
+	read func(slaveId byte, address uint16, quantity uint16) (*common.BitVector, error)) (*common.BitVector, error) {
+	if err := checkRange(address, int(quantity)); err != nil {
+		return nil, err
+	}
+	limit := int(c.BlockLimits(slaveId).ReadBits)
+	bits := common.NewBitVector(uint(quantity))
+	for done := 0; done < int(quantity); done += limit {
+		blockAddress := address + uint16(done)
+		blockQuantity := uint16(min(limit, int(quantity)-done))
+		block, err := read(slaveId, blockAddress, blockQuantity)
+		if err == nil {
+			err = bits.TrySetRange(uint(done), block.ToBooleans())
+		}
+		if err != nil {
+			return bits.Slice(0, uint(done)),
+				&RangeError{Address: blockAddress, Quantity: blockQuantity, Done: uint16(done), Err: err}
+		}
+	}
+	return bits, nil
+}
+
+// writeRegistersRange 分块写入寄存器，块大小为 align 的整数倍，避免多寄存器数值被拆分到两次请求中
+func (c *ModbusMaster) writeRegistersRange(slaveId byte, address uint16, values []uint16, align int) error {
+	if err := checkRange(address, len(values)); err != nil {
+		return err
+	}
+	limit := alignLimit(int(c.BlockLimits(slaveId).WriteRegisters), align)
+	for done := 0; done < len(values); done += limit {
+		block := values[done:min(done+limit, len(values))]
+		blockAddress := address + uint16(done)
+		if err := c.WriteMultipleRegisterValues(slaveId, blockAddress, block); err != nil {
+			return &RangeError{Address: blockAddress, Quantity: uint16(len(block)), Done: uint16(done), Err: err}
+		}
+	}
+	return nil
+}
+
+// readRegistersRange 分块读取保持寄存器或输入寄存器到同一个切片，块大小为 align 的整数倍，失败时返回已读取的部分
+func (c *ModbusMaster) readRegistersRange(slaveId byte, functionCode byte, address uint16, quantity uint16, align int) ([]uint16, error) {
+	if err := checkRange(address, int(quantity)); err != nil {
+		return nil, err
+	}
+	limit := alignLimit(int(c.BlockLimits(slaveId).ReadRegisters), align)
+	values := make([]uint16, quantity)
+	for done := 0; done < int(quantity); done += limit {
+		block := values[done:min(done+limit, int(quantity))]
+		blockAddress := address + uint16(done)
+		if err := c.readRegistersInto(slaveId, functionCode, blockAddress, block); err != nil {
+			return values[:done], &RangeError{Address: blockAddress, Quantity: uint16(len(block)), Done: uint16(done), Err: err}
+		}
+	}
+	return values, nil
+}
+
+// registersRange 将分块读取的结果转换为寄存器
+func registersRange(values []uint16, err error) ([]*common.Register, error) {
+	if values == nil {
+		return nil, err
+	}
+	return common.NewRegistersFromUInt16s(values), err
+}
+
+// alignLimit 将块大小向下取整为 align 的整数倍
+func alignLimit(limit int, align int) int {
+	if align > 1 && limit >= align {
+		return limit / align * align
+	}
+	return limit
+}
+
+// checkRange 检查数量不为 0 且地址范围不超过 65535
+func checkRange(address uint16, quantity int) error {
+	if quantity < 1 {
+		return fmt.Errorf("modbus: quantity '%v' must not be zero", quantity)
+	}
+	if int(address)+quantity > 0x10000 {
+		return fmt.Errorf("modbus: address range '%v' + '%v' exceeds '%v'", address, quantity, 0xFFFF)
+	}
+	return nil
+}
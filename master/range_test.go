@@ -0,0 +1,195 @@
+package master_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// startRangeSlave 启动从站，起始地址为 failAddress 的请求以非法功能异常响应
+func startRangeSlave(t *testing.T, store *slave.MemoryDataStore, failAddress int) (*slave.ModbusSlave, *master.ModbusMaster) {
+	t.Helper()
+	s := slave.NewModbusTCPSlave(1, &slave.DeviceInfo{}, store)
+	err := s.SetAuthorizer(func(ctx context.Context, request *common.ProtocolDataUnit) bool {
+		return len(request.Data) < 2 || int(binary.BigEndian.Uint16(request.Data)) != failAddress
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, master.NewModbusTCPMasterWithAddress(startNetServer(t, &s.ModbusDevice))
+}
+
+func TestRegistersRange(t *testing.T) {
+	store := slave.NewMemoryDataStore()
+	want := make([]uint16, 250)
+	for i := range want {
+		want[i] = uint16(1000 + i)
+	}
+	store.WriteRegisters(slave.PointTypeHoldingRegister, 5, want)
+	s, m := startRangeSlave(t, store, -1)
+	if err := m.SetBlockLimits(1, master.BlockLimits{ReadRegisters: 100, WriteRegisters: 40}); err != nil {
+		t.Fatal(err)
+	}
+
+	registers, err := m.ReadHoldingRegistersRange(1, 5, 250)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values := common.RegisterValues(registers); !slices.Equal(values, want) {
+		t.Fatalf("registers = %v, want %v", values, want)
+	}
+	if requests := s.Handler.Counters().ServerMessageCount; requests != 3 {
+		t.Fatalf("read requests = %v, want 3", requests)
+	}
+
+	written := make([]uint16, 100)
+	for i := range written {
+		written[i] = uint16(i)
+	}
+	if err = m.WriteMultipleRegistersRange(1, 300, common.NewRegistersFromUInt16s(written)); err != nil {
+		t.Fatal(err)
+	}
+	if values := store.ReadRegisters(slave.PointTypeHoldingRegister, 300, 100); !slices.Equal(values, written) {
+		t.Fatalf("written registers = %v, want %v", values, written)
+	}
+	if requests := s.Handler.Counters().ServerMessageCount; requests != 6 {
+		t.Fatalf("write requests = %v, want 3", requests-3)
+	}
+}
+
+func TestReadCoilsRange(t *testing.T) {
+	store := slave.NewMemoryDataStore()
+	want := make([]bool, 40)
+	for i := range want {
+		want[i] = i%3 == 0
+	}
+	store.WriteBits(slave.PointTypeCoil, 10, common.NewBitVectorFromBooleans(want))
+	s, m := startRangeSlave(t, store, -1)
+	if err := m.SetBlockLimits(1, master.BlockLimits{ReadBits: 16}); err != nil {
+		t.Fatal(err)
+	}
+
+	bits, err := m.ReadCoilsRange(1, 10, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values := bits.ToBooleans(); !slices.Equal(values, want) {
+		t.Fatalf("coils = %v, want %v", values, want)
+	}
+	if requests := s.Handler.Counters().ServerMessageCount; requests != 3 {
+		t.Fatalf("requests = %v, want 3", requests)
+	}
+}
+
+func TestSetBlockLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  master.BlockLimits
+		wantErr bool
+	}{
+		{name: "protocol maximum", limits: master.BlockLimits{ReadBits: 2000, ReadRegisters: 125, WriteBits: 1968, WriteRegisters: 123}},
+		{name: "read bits", limits: master.BlockLimits{ReadBits: 2001}, wantErr: true},
+		{name: "read registers", limits: master.BlockLimits{ReadRegisters: 126}, wantErr: true},
+		{name: "write bits", limits: master.BlockLimits{WriteBits: 1969}, wantErr: true},
+		{name: "write registers", limits: master.BlockLimits{WriteRegisters: 124}, wantErr: true},
+	}
+	m := master.NewModbusTCPMasterWithAddress("127.0.0.1:502")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := m.SetBlockLimits(1, tt.limits); (err != nil) != tt.wantErr {
+				t.Fatalf("got %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+	// 失败的设置不覆盖之前的值，未设置的项为规范规定的最大值
+	if limits := m.BlockLimits(1); limits != (master.BlockLimits{ReadBits: 2000, ReadRegisters: 125, WriteBits: 1968, WriteRegisters: 123}) {
+		t.Fatalf("limits = %+v", limits)
+	}
+	if limits := m.BlockLimits(2); limits.ReadRegisters != master.MaxReadRegisters {
+		t.Fatalf("default limits = %+v", limits)
+	}
+}
+
+// TestRangeOutOfAddressSpace 地址范围超过 0xFFFF 或数量为 0 时不发送请求
+func TestRangeOutOfAddressSpace(t *testing.T) {
+	s, m := startRangeSlave(t, slave.NewMemoryDataStore(), -1)
+	if _, err := m.ReadHoldingRegistersRange(1, 0xFFFF, 2); err == nil {
+		t.Fatal("read past 0xFFFF: got nil error")
+	}
+	if _, err := m.ReadCoilsRange(1, 0xFFF0, 17); err == nil {
+		t.Fatal("read coils past 0xFFFF: got nil error")
+	}
+	if _, err := m.ReadHoldingRegistersRange(1, 0, 0); err == nil {
+		t.Fatal("read zero registers: got nil error")
+	}
+	if err := m.WriteMultipleRegistersRange(1, 0xFFFE, common.NewRegistersFromUInt16s([]uint16{1, 2, 3})); err == nil {
+		t.Fatal("write past 0xFFFF: got nil error")
+	}
+	if requests := s.Handler.Counters().ServerMessageCount; requests != 0 {
+		t.Fatalf("requests = %v, want 0", requests)
+	}
+	if _, err := m.ReadHoldingRegistersRange(1, 0xFFFF, 1); err != nil {
+		t.Fatalf("read last register: %v", err)
+	}
+}
+
+// TestRangeError 中间的块失败时返回已完成的部分结果和失败块的位置
+func TestRangeError(t *testing.T) {
+	store := slave.NewMemoryDataStore()
+	for i := uint16(0); i < 150; i++ {
+		store.Write(slave.PointTypeHoldingRegister, i, i)
+		store.Write(slave.PointTypeCoil, i, 1)
+	}
+	_, m := startRangeSlave(t, store, 50)
+	if err := m.SetBlockLimits(1, master.BlockLimits{ReadBits: 25, ReadRegisters: 25, WriteRegisters: 25}); err != nil {
+		t.Fatal(err)
+	}
+	checkRangeError := func(t *testing.T, err error) {
+		t.Helper()
+		var rangeError *master.RangeError
+		if !errors.As(err, &rangeError) {
+			t.Fatalf("got %v, want range error", err)
+		}
+		if rangeError.Address != 50 || rangeError.Quantity != 25 || rangeError.Done != 50 {
+			t.Fatalf("range error = %+v, want address 50 quantity 25 done 50", rangeError)
+		}
+		var exception *common.Error
+		if !errors.As(err, &exception) || exception.ExceptionCode != common.ExceptionCodeIllegalFunction {
+			t.Fatalf("got %v, want illegal function exception", err)
+		}
+	}
+
+	t.Run("read registers", func(t *testing.T) {
+		registers, err := m.ReadHoldingRegistersRange(1, 0, 100)
+		checkRangeError(t, err)
+		if values := common.RegisterValues(registers); len(values) != 50 || values[49] != 49 {
+			t.Fatalf("partial registers = %v, want 0..49", values)
+		}
+	})
+	t.Run("read coils", func(t *testing.T) {
+		bits, err := m.ReadCoilsRange(1, 0, 100)
+		checkRangeError(t, err)
+		if bits.Size() != 50 || bits.Count() != 50 {
+			t.Fatalf("partial coils = %v", bits.ToString())
+		}
+	})
+	t.Run("write registers", func(t *testing.T) {
+		values := make([]uint16, 100)
+		for i := range values {
+			values[i] = 0xAAAA
+		}
+		checkRangeError(t, m.WriteMultipleRegistersRange(1, 0, common.NewRegistersFromUInt16s(values)))
+		if v := store.Read(slave.PointTypeHoldingRegister, 49); v != 0xAAAA {
+			t.Fatalf("register 49 = %#x, want written", v)
+		}
+		if v := store.Read(slave.PointTypeHoldingRegister, 75); v != 75 {
+			t.Fatalf("register 75 = %#x, want unchanged", v)
+		}
+	})
+}
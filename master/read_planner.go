@@ -0,0 +1,212 @@
+package master
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// ReadItem 读取计划中的一项请求，FunctionCode 为 0x01-0x04 之一
+type ReadItem struct {
+	FunctionCode byte
+	Address      uint16
+	Quantity     uint16
+}
+
+// ReadBlock 合并后的一次读取请求，Items 为其覆盖的请求项在输入中的下标
+type ReadBlock struct {
+	FunctionCode byte
+	Address      uint16
+	Quantity     uint16
+	Items        []int
+}
+
+// ReadResult 单个请求项的读取结果，位数据保存在 Bits，寄存器值保存在 Values，所在块读取失败时 Err 不为 nil
+type ReadResult struct {
+	Bits   []bool
+	Values []uint16
+	Err    error
+}
+
+// addressRange 地址区间 [start, end)
+type addressRange struct {
+	start, end int
+}
+
+// ReadPlanner 将分散的读取请求合并为尽量少的 FC01-04 请求
+type ReadPlanner struct {
+	// MaxGap 合并时允许读取的最大空隙（点数），空隙中的数据会被读取但丢弃
+	MaxGap uint16
+	// Limits 单次请求的最大数量，仅 ReadBits 和 ReadRegisters 有效，为 0 时使用设备的块大小，超过规范规定的最大值时返回错误
+	Limits BlockLimits
+	// holes 按功能码记录的不可读取区间，合并时不会跨越
+	holes map[byte][]addressRange
+}
+
+// AddHole 登记设备不可读取的地址区间（读取时返回非法数据地址），合并时不会读取这些地址
+func (p *ReadPlanner) AddHole(functionCode byte, address uint16, quantity uint16) error {
+	if err := checkReadFunctionCode(functionCode); err != nil {
+		return err
+	}
+	if err := checkRange(address, int(quantity)); err != nil {
+		return err
+	}
+	if p.holes == nil {
+		p.holes = make(map[byte][]addressRange)
+	}
+	p.holes[functionCode] = append(p.holes[functionCode], addressRange{int(address), int(address) + int(quantity)})
+	return nil
+}
+
+// Plan 按 Limits 生成读取计划，Limits 未设置的项使用规范规定的最大值
+func (p *ReadPlanner) Plan(items []ReadItem) ([]*ReadBlock, error) {
+	return p.plan(items, p.Limits.withDefaults())
+}
+
+// Read 按计划从设备读取并将结果分发到各请求项，results 与 items 一一对应。
+// 某个块读取失败时，其覆盖的请求项的 Err 为该错误，其余块继续读取，返回的 err 汇总所有失败块的错误
+func (p *ReadPlanner) Read(c *ModbusMaster, slaveId byte, items []ReadItem) (results []*ReadResult, err error) {
+	limits := p.Limits
+	deviceLimits := c.BlockLimits(slaveId)
+	if limits.ReadBits == 0 {
+		limits.ReadBits = deviceLimits.ReadBits
+	}
+	if limits.ReadRegisters == 0 {
+		limits.ReadRegisters = deviceLimits.ReadRegisters
+	}
+	blocks, err := p.plan(items, limits)
+	if err != nil {
+		return
+	}
+
+	results = make([]*ReadResult, len(items))
+	for i := range results {
+		results[i] = &ReadResult{}
+	}
+	var errs []error
+	for _, block := range blocks {
+		var bits *common.BitVector
+		var values []uint16
+		var blockErr error
+		switch block.FunctionCode {
+		case common.FuncCodeReadCoils:
+			bits, blockErr = c.ReadCoils(slaveId, block.Address, block.Quantity)
+		case common.FuncCodeReadDiscreteInputs:
+			bits, blockErr = c.ReadDiscreteInputs(slaveId, block.Address, block.Quantity)
+		case common.FuncCodeReadHoldingRegisters:
+			values, blockErr = c.ReadHoldingRegisterValues(slaveId, block.Address, block.Quantity)
+		case common.FuncCodeReadInputRegisters:
+			values, blockErr = c.ReadInputRegisterValues(slaveId, block.Address, block.Quantity)
+		}
+		if blockErr == nil && bits == nil && len(values) != int(block.Quantity) {
+			blockErr = fmt.Errorf("modbus: response quantity '%v' does not match request '%v'", len(values), block.Quantity)
+		}
+		if blockErr != nil {
+			blockErr = &RangeError{Address: block.Address, Quantity: block.Quantity, Err: blockErr}
+			errs = append(errs, blockErr)
+		}
+		for _, index := range block.Items {
+			item := items[index]
+			result := results[index]
+			if blockErr != nil {
+				result.Err = blockErr
+				continue
+			}
+			offset := int(item.Address - block.Address)
+			if bits != nil {
+				result.Bits = bits.GetRange(uint(offset), uint(item.Quantity))
+			} else {
+				result.Values = values[offset : offset+int(item.Quantity)]
+			}
+		}
+	}
+	err = errors.Join(errs...)
+	return
+}
+
+// plan 按功能码分组，将地址相近的请求项合并为不超过 limits 且不跨越不可读取区间的块
+func (p *ReadPlanner) plan(items []ReadItem, limits BlockLimits) ([]*ReadBlock, error) {
+	if limits.ReadBits > MaxReadBits || limits.ReadRegisters > MaxReadRegisters {
+		return nil, fmt.Errorf("modbus: read limits '%v' and '%v' exceed the protocol maximum '%v' and '%v'",
+			limits.ReadBits, limits.ReadRegisters, MaxReadBits, MaxReadRegisters)
+	}
+	groups := make(map[byte][]int)
+	for i, item := range items {
+		if err := checkReadFunctionCode(item.FunctionCode); err != nil {
+			return nil, err
+		}
+		if err := checkRange(item.Address, int(item.Quantity)); err != nil {
+			return nil, err
+		}
+		if int(item.Quantity) > blockLimit(item.FunctionCode, limits) {
+			return nil, fmt.Errorf("modbus: quantity '%v' of item '%v' exceeds block limit '%v'", item.Quantity, i, blockLimit(item.FunctionCode, limits))
+		}
+		groups[item.FunctionCode] = append(groups[item.FunctionCode], i)
+	}
+
+	var blocks []*ReadBlock
+	for _, functionCode := range []byte{
+		common.FuncCodeReadCoils,
+		common.FuncCodeReadDiscreteInputs,
+		common.FuncCodeReadHoldingRegisters,
+		common.FuncCodeReadInputRegisters,
+	} {
+		indexes := groups[functionCode]
+		sort.SliceStable(indexes, func(a, b int) bool {
+			return items[indexes[a]].Address < items[indexes[b]].Address
+		})
+		limit := blockLimit(functionCode, limits)
+		var block *ReadBlock
+		var start, end int
+		for _, index := range indexes {
+			itemStart := int(items[index].Address)
+			itemEnd := itemStart + int(items[index].Quantity)
+			if block != nil &&
+				itemStart <= end+int(p.MaxGap) &&
+				max(end, itemEnd)-start <= limit &&
+				!p.overlapsHole(functionCode, end, itemStart) {
+				end = max(end, itemEnd)
+				block.Quantity = uint16(end - start)
+				block.Items = append(block.Items, index)
+				continue
+			}
+			start, end = itemStart, itemEnd
+			block = &ReadBlock{
+				FunctionCode: functionCode,
+				Address:      uint16(start),
+				Quantity:     uint16(end - start),
+				Items:        []int{index},
+			}
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks, nil
+}
+
+// overlapsHole 判断空隙 [start, end) 是否与不可读取区间重叠
+func (p *ReadPlanner) overlapsHole(functionCode byte, start, end int) bool {
+	for _, hole := range p.holes[functionCode] {
+		if hole.start < end && start < hole.end {
+			return true
+		}
+	}
+	return false
+}
+
+// blockLimit 返回功能码对应的单次请求最大数量
+func blockLimit(functionCode byte, limits BlockLimits) int {
+	if functionCode == common.FuncCodeReadCoils || functionCode == common.FuncCodeReadDiscreteInputs {
+		return int(limits.ReadBits)
+	}
+	return int(limits.ReadRegisters)
+}
+
+// checkReadFunctionCode 检查功能码为 0x01-0x04 之一
+func checkReadFunctionCode(functionCode byte) error {
+	if functionCode < common.FuncCodeReadCoils || functionCode > common.FuncCodeReadInputRegisters {
+		return fmt.Errorf("modbus: function code '%v' is out of range [1, 4]", functionCode)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package master_test
+
+import (
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+func TestReadPlannerPlanLimits(t *testing.T) {
+	items := []master.ReadItem{
+		{FunctionCode: common.FuncCodeReadHoldingRegisters, Address: 0, Quantity: 10},
+		{FunctionCode: common.FuncCodeReadHoldingRegisters, Address: 110, Quantity: 10},
+		{FunctionCode: common.FuncCodeReadHoldingRegisters, Address: 200, Quantity: 10},
+	}
+	tests := []struct {
+		name    string
+		limits  master.BlockLimits
+		blocks  int
+		wantErr bool
+	}{
+		{name: "default", blocks: 2},
+		{name: "protocol maximum", limits: master.BlockLimits{ReadRegisters: 125}, blocks: 2},
+		{name: "small", limits: master.BlockLimits{ReadRegisters: 50}, blocks: 3},
+		{name: "256 registers", limits: master.BlockLimits{ReadRegisters: 256}, wantErr: true},
+		{name: "300 registers", limits: master.BlockLimits{ReadRegisters: 300}, wantErr: true},
+		{name: "2001 bits", limits: master.BlockLimits{ReadBits: 2001}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planner := &master.ReadPlanner{MaxGap: 100, Limits: tt.limits}
+			blocks, err := planner.Plan(items)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %v blocks, want error", len(blocks))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(blocks) != tt.blocks {
+				t.Fatalf("got %v blocks, want %v", len(blocks), tt.blocks)
+			}
+		})
+	}
+}
+
+func TestReadPlannerRead(t *testing.T) {
+	store := slave.NewMemoryDataStore()
+	for address := uint16(0); address < 20; address++ {
+		store.Write(slave.PointTypeHoldingRegister, address, 100+address)
+	}
+	m := master.NewModbusTCPMasterWithAddress(startTCPSlave(t, 1, store))
+	items := []master.ReadItem{
+		{FunctionCode: common.FuncCodeReadHoldingRegisters, Address: 12, Quantity: 2},
+		{FunctionCode: common.FuncCodeReadHoldingRegisters, Address: 3, Quantity: 1},
+	}
+
+	planner := &master.ReadPlanner{MaxGap: 10}
+	results, err := planner.Read(m, 1, items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, item := range items {
+		for j, value := range results[i].Values {
+			if want := 100 + item.Address + uint16(j); value != want {
+				t.Fatalf("item %v value %v = %v, want %v", i, j, value, want)
+			}
+		}
+	}
+
+	planner.Limits.ReadRegisters = 256
+	if _, err = planner.Read(m, 1, items); err == nil {
+		t.Fatal("read with 256 register limit: got nil error")
+	}
+}
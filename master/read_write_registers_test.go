@@ -0,0 +1,57 @@
+package master_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// TestReadWriteMultipleRegisters 从站先写后读，读取区间与写入区间重叠时读到的是写入后的值
+func TestReadWriteMultipleRegisters(t *testing.T) {
+	tests := []struct {
+		name         string
+		readAddress  uint16
+		readQuantity uint16
+		writeAddress uint16
+		values       []uint16
+		want         []uint16
+	}{
+		{name: "disjoint", readAddress: 0, readQuantity: 3, writeAddress: 5, values: []uint16{1, 2}, want: []uint16{100, 101, 102}},
+		{name: "overlapping", readAddress: 3, readQuantity: 4, writeAddress: 4, values: []uint16{7, 8}, want: []uint16{103, 7, 8, 106}},
+		{name: "same range", readAddress: 2, readQuantity: 2, writeAddress: 2, values: []uint16{9, 9}, want: []uint16{9, 9}},
+		{name: "read inside write", readAddress: 6, readQuantity: 1, writeAddress: 5, values: []uint16{11, 12, 13}, want: []uint16{12}},
+	}
+	store := slave.NewMemoryDataStore()
+	m := master.NewModbusTCPMasterWithAddress(startTCPSlave(t, 1, store))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for address := uint16(0); address < 10; address++ {
+				store.Write(slave.PointTypeHoldingRegister, address, 100+address)
+			}
+			results, err := m.ReadWriteMultipleRegisters(1, tt.readAddress, tt.readQuantity, tt.writeAddress, common.NewRegistersFromUInt16s(tt.values))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := common.RegisterValues(results); !slices.Equal(got, tt.want) {
+				t.Fatalf("read = %v, want %v", got, tt.want)
+			}
+			for i, value := range tt.values {
+				if got := store.Read(slave.PointTypeHoldingRegister, tt.writeAddress+uint16(i)); got != value {
+					t.Fatalf("register %v = %v, want %v", tt.writeAddress+uint16(i), got, value)
+				}
+			}
+
+			// []uint16 接口的行为相同
+			values, err := m.ReadWriteMultipleRegisterValues(1, tt.readAddress, tt.readQuantity, tt.writeAddress, tt.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(values, tt.want) {
+				t.Fatalf("read values = %v, want %v", values, tt.want)
+			}
+		})
+	}
+}
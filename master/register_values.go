@@ -0,0 +1,153 @@
+package master
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// 以 []uint16 读写寄存器，避免为每个寄存器分配 *common.Register；Into 方法读取到调用方提供的 dst 中，不分配寄存器内存
+
+// ReadHoldingRegisterValues 读取 quantity 个保持寄存器的值 (功能码 0x03)
+func (c *ModbusMaster) ReadHoldingRegisterValues(slaveId byte, address uint16, quantity uint16) ([]uint16, error) {
+	return c.readRegisterValues(slaveId, common.FuncCodeReadHoldingRegisters, address, quantity)
+}
+
+// ReadHoldingRegistersInto 读取 len(dst) 个保持寄存器的值到 dst (功能码 0x03)
+func (c *ModbusMaster) ReadHoldingRegistersInto(slaveId byte, address uint16, dst []uint16) error {
+	return c.readRegistersInto(slaveId, common.FuncCodeReadHoldingRegisters, address, dst)
+}
+
+// ReadInputRegisterValues 读取 quantity 个输入寄存器的值 (功能码 0x04)
+func (c *ModbusMaster) ReadInputRegisterValues(slaveId byte, address uint16, quantity uint16) ([]uint16, error) {
+	return c.readRegisterValues(slaveId, common.FuncCodeReadInputRegisters, address, quantity)
+}
+
+// ReadInputRegistersInto 读取 len(dst) 个输入寄存器的值到 dst (功能码 0x04)
+func (c *ModbusMaster) ReadInputRegistersInto(slaveId byte, address uint16, dst []uint16) error {
+	return c.readRegistersInto(slaveId, common.FuncCodeReadInputRegisters, address, dst)
+}
+
+// WriteMultipleRegisterValues 写入多个保持寄存器 (功能码 0x10)
+func (c *ModbusMaster) WriteMultipleRegisterValues(slaveId byte, address uint16, values []uint16) (err error) {
+	quantity := len(values)
+	if quantity < 1 || quantity > 123 {
+		err = fmt.Errorf("modbus: quantity '%v' is out of range [1, 123]", quantity)
+		return
+	}
+	data := make([]byte, 5, 5+quantity*2)
+	binary.BigEndian.PutUint16(data, address)
+	binary.BigEndian.PutUint16(data[2:], uint16(quantity))
+	data[4] = byte(quantity * 2)
+	request := &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeWriteMultipleRegisters,
+		Data:         common.WordsToBytes(data, values),
+	}
+	response, err := c.send(slaveId, request)
+	if err != nil {
+		return
+	}
+	// Fixed response length
+	if len(response.Data) != 4 {
+		err = fmt.Errorf("modbus: response data size '%v' does not match expected '%v'", len(response.Data), 4)
+		return
+	}
+	respValue := binary.BigEndian.Uint16(response.Data)
+	if address != respValue {
+		err = fmt.Errorf("modbus: response address '%v' does not match request '%v'", respValue, address)
+		return
+	}
+	respValue = binary.BigEndian.Uint16(response.Data[2:])
+	if uint16(quantity) != respValue {
+		err = fmt.Errorf("modbus: response quantity '%v' does not match request '%v'", respValue, quantity)
+		return
+	}
+	return
+}
+
+// ReadWriteMultipleRegisterValues 先写入 values 再读取 readQuantity 个保持寄存器的值 (功能码 0x17)
+func (c *ModbusMaster) ReadWriteMultipleRegisterValues(slaveId byte, readAddress uint16, readQuantity uint16, writeAddress uint16, values []uint16) (results []uint16, err error) {
+	if readQuantity < 1 || readQuantity > 125 {
+		err = fmt.Errorf("modbus: read quantity '%v' is out of range [1, 125]", readQuantity)
+		return
+	}
+	writeQuantity := len(values)
+	if writeQuantity < 1 || writeQuantity > 121 {
+		err = fmt.Errorf("modbus: write quantity '%v' is out of range [1, 121]", writeQuantity)
+		return
+	}
+	data := make([]byte, 9, 9+writeQuantity*2)
+	binary.BigEndian.PutUint16(data, readAddress)
+	binary.BigEndian.PutUint16(data[2:], readQuantity)
+	binary.BigEndian.PutUint16(data[4:], writeAddress)
+	binary.BigEndian.PutUint16(data[6:], uint16(writeQuantity))
+	data[8] = byte(writeQuantity * 2)
+	request := &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeReadWriteMultipleRegisters,
+		Data:         common.WordsToBytes(data, values),
+	}
+	response, err := c.send(slaveId, request)
+	if err != nil {
+		return
+	}
+	count := int(response.Data[0])
+	length := len(response.Data) - 1
+	if count != length {
+		err = fmt.Errorf("modbus: response data size '%v' does not match count '%v'", length, count)
+		return
+	}
+	if count != int(readQuantity)*2 {
+		err = fmt.Errorf("modbus: response byte count '%v' does not match read quantity '%v'", count, readQuantity)
+		return
+	}
+	results = common.BytesToWords(make([]uint16, 0, readQuantity), response.Data[1:])
+	return
+}
+
+// readRegisters 读取保持寄存器或输入寄存器，返回校验字节数后的寄存器数据
+func (c *ModbusMaster) readRegisters(slaveId byte, functionCode byte, address uint16, quantity uint16) (data []byte, err error) {
+	if quantity < 1 || quantity > 125 {
+		err = fmt.Errorf("modbus: quantity '%v' is out of range [1, 125]", quantity)
+		return
+	}
+	request := &common.ProtocolDataUnit{FunctionCode: functionCode}
+	request.LoadData(address, quantity)
+	response, err := c.send(slaveId, request)
+	if err != nil {
+		return
+	}
+	count := int(response.Data[0])
+	length := len(response.Data) - 1
+	if count != length {
+		err = fmt.Errorf("modbus: response data size '%v' does not match count '%v'", length, count)
+		return
+	}
+	data = response.Data[1:]
+	return
+}
+
+// readRegisterValues 读取寄存器并解码为 uint16
+func (c *ModbusMaster) readRegisterValues(slaveId byte, functionCode byte, address uint16, quantity uint16) ([]uint16, error) {
+	data, err := c.readRegisters(slaveId, functionCode, address, quantity)
+	if err != nil {
+		return nil, err
+	}
+	return common.BytesToWords(make([]uint16, 0, len(data)/2), data), nil
+}
+
+// readRegistersInto 读取 len(dst) 个寄存器到 dst，响应数量必须与请求一致
+func (c *ModbusMaster) readRegistersInto(slaveId byte, functionCode byte, address uint16, dst []uint16) error {
+	if len(dst) > 0xFFFF {
+		return fmt.Errorf("modbus: quantity '%v' is out of range [1, 125]", len(dst))
+	}
+	data, err := c.readRegisters(slaveId, functionCode, address, uint16(len(dst)))
+	if err != nil {
+		return err
+	}
+	if len(data) != len(dst)*2 {
+		return fmt.Errorf("modbus: response quantity '%v' does not match request '%v'", len(data)/2, len(dst))
+	}
+	common.BytesToWords(dst[:0], data)
+	return nil
+}
@@ -0,0 +1,79 @@
+package master_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/panjf2000/gnet/v2"
+	"github.com/panjf2000/gnet/v2/pkg/logging"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// startTCPSlave 在本地空闲端口启动进程内 TCP 从站，返回监听地址
+func startTCPSlave(tb testing.TB, slaveId byte, store *slave.MemoryDataStore) string {
+	tb.Helper()
+	return startNetServer(tb, &slave.NewModbusTCPSlave(slaveId, &slave.DeviceInfo{}, store).ModbusDevice)
+}
+
+// startNetServer 在本地空闲端口启动注册了 devices 的进程内 NetServer，返回监听地址
+func startNetServer(tb testing.TB, devices ...*common.ModbusDevice) string {
+	tb.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	server := common.NewNetServer()
+	for _, device := range devices {
+		server.Enroll(device)
+	}
+	protoAddr := "tcp://" + address
+	go func() { _ = gnet.Run(server, protoAddr, gnet.WithLogLevel(logging.ErrorLevel)) }()
+	tb.Cleanup(func() { _ = gnet.Stop(context.Background(), protoAddr) })
+
+	for i := 0; i < 50; i++ {
+		conn, err := net.Dial("tcp", address)
+		if err == nil {
+			_ = conn.Close()
+			return address
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	tb.Fatal(fmt.Errorf("net server '%v' did not start", address))
+	return ""
+}
+
+// BenchmarkReadHoldingRegisters 只使用 []*common.Register 接口，在引入 []uint16 接口之前的版本上同样可以运行，用作对比基线
+func BenchmarkReadHoldingRegisters(b *testing.B) {
+	address := startTCPSlave(b, 1, slave.NewMemoryDataStore())
+	m := master.NewModbusTCPMasterWithAddress(address)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := m.ReadHoldingRegisters(1, 0, 125); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkReadHoldingRegistersInto 读取到调用方提供的缓冲区
+func BenchmarkReadHoldingRegistersInto(b *testing.B) {
+	address := startTCPSlave(b, 1, slave.NewMemoryDataStore())
+	m := master.NewModbusTCPMasterWithAddress(address)
+	dst := make([]uint16, 125)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := m.ReadHoldingRegistersInto(1, 0, dst); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package master
+
+import (
+	"io"
+	"time"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// NewModbusRTUMasterWithConfig 使用串口配置创建 RTU 主站
+func NewModbusRTUMasterWithConfig(config common.SerialConfig) *ModbusMaster {
+	message := &common.RTUMessage{}
+	serialClient := common.NewSerialClient(config)
+	return NewModbusMaster(message, NewRTUTransport(&serialClient))
+}
+
+func NewModbusRTUMaster(client *common.SerialClient) *ModbusMaster {
+	message := &common.RTUMessage{}
+	return NewModbusMaster(message, NewRTUTransport(client))
+}
+
+// RTUTransport Modbus RTU 串口传输定义，实现 Transport 接口
+type RTUTransport struct {
+	client *common.SerialClient
+	// CharTimeout 响应帧中两个字符的最大间隔，超过时丢弃不完整的响应，零值表示不检查
+	CharTimeout time.Duration
+	// ResponseLength 计算响应长度的函数，用于内置计算无法确定响应长度的自定义功能码，为 nil 时只使用内置的计算
+	ResponseLength common.ResponseLengthFunc
+}
+
+// NewRTUTransport 创建 RTU 串口传输，CharTimeout 按串口波特率设置，见 common.SerialConfig.RTUCharTimeout
+func NewRTUTransport(client *common.SerialClient) *RTUTransport {
+	return &RTUTransport{client: client, CharTimeout: client.Config.RTUCharTimeout()}
+}
+
+// Send 发送数据到串口，并按功能码读取完整的响应帧
+func (t *RTUTransport) Send(requestData []byte) (responseData []byte, err error) {
+	err = t.client.Send(requestData, func(r io.Reader) error {
+		message := &common.RTUFrame{ResponseLength: t.ResponseLength}
+		if e := message.ReadFromConn(requestData, common.NewCharTimeoutReader(r, t.CharTimeout)); e != nil {
+			return e
+		}
+		responseData = message.ToBytes()
+		message.Release()
+		return nil
+	})
+	return
+}
+
+// Transmit 发送数据到串口，不等待响应
+func (t *RTUTransport) Transmit(requestData []byte) error {
+	return t.client.Send(requestData, nil)
+}
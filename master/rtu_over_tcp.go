@@ -1,40 +1,61 @@
 package master
 
 import (
-	"github.com/veryinf/modbus-kit/common"
 	"net"
+	"time"
+
+	"github.com/veryinf/modbus-kit/common"
 )
 
+// NewModbusRTUOverTCPMasterWithAddress 创建 RTU over TCP 主站，请求之间保持 Modbus 串口默认波特率 19200 的帧间隔，
+// 串口服务器使用其它波特率时使用 NewModbusRTUOverTCPMasterWithBaud
 func NewModbusRTUOverTCPMasterWithAddress(address string) *ModbusMaster {
-	message := &common.RTUMessage{}
 	tcpClient := common.NewTCPClient(address)
-	transport := &RTUOverTCPTransport{
-		client: &tcpClient,
-	}
-	return NewModbusMaster(message, transport)
+	tcpClient.FrameDelay = common.RTUFrameDelay(19200)
+	return NewModbusRTUOverTCPMaster(&tcpClient)
+}
+
+// NewModbusRTUOverTCPMasterWithBaud 创建通过透传串口服务器访问 RS-485 总线的 RTU 主站，
+// 按串口波特率设置请求之间的帧间隔 (3.5 字符时间)，避免连续的请求在总线上连成一帧。
+// 网络传输和串口服务器的分包会在一个响应中引入远大于字符时间的间隔，因此不检查响应的字符间隔
+func NewModbusRTUOverTCPMasterWithBaud(address string, baudRate int) *ModbusMaster {
+	tcpClient := common.NewTCPClient(address)
+	tcpClient.FrameDelay = common.RTUFrameDelay(baudRate)
+	return NewModbusRTUOverTCPMaster(&tcpClient)
 }
 
 func NewModbusRTUOverTCPMaster(client *common.TCPClient) *ModbusMaster {
 	message := &common.RTUMessage{}
-	transport := &RTUOverTCPTransport{
-		client: client,
-	}
-	return NewModbusMaster(message, transport)
+	return NewModbusMaster(message, NewRTUOverTCPTransport(client))
 }
 
 type RTUOverTCPTransport struct {
 	client *common.TCPClient
+	// CharTimeout 响应帧中两次收到数据的最大间隔，超过时丢弃不完整的响应，零值表示不检查
+	CharTimeout time.Duration
+	// ResponseLength 计算响应长度的函数，用于内置计算无法确定响应长度的自定义功能码，为 nil 时只使用内置的计算
+	ResponseLength common.ResponseLengthFunc
+}
+
+func NewRTUOverTCPTransport(client *common.TCPClient) *RTUOverTCPTransport {
+	return &RTUOverTCPTransport{client: client}
 }
 
 // Send 发送数据到服务器，并确保响应长度大于头部长度
 func (t *RTUOverTCPTransport) Send(requestData []byte) (responseData []byte, err error) {
 	err = t.client.Send(requestData, func(conn net.Conn) error {
-		message := &common.RTUFrame{}
-		if e := message.ReadFromConn(requestData, conn); e != nil {
+		message := &common.RTUFrame{ResponseLength: t.ResponseLength}
+		if e := message.ReadFromConn(requestData, common.NewCharTimeoutReader(conn, t.CharTimeout)); e != nil {
 			return e
 		}
 		responseData = message.ToBytes()
+		message.Release()
 		return nil
 	})
 	return
 }
+
+// Transmit 发送数据到服务器，不等待响应
+func (t *RTUOverTCPTransport) Transmit(requestData []byte) error {
+	return t.client.Send(requestData, nil)
+}
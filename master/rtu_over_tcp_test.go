@@ -0,0 +1,47 @@
+package master_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+)
+
+// TestRTUOverTCPMasterWithBaudSplitResponse 串口服务器分两次发送的响应，中间的间隔远大于字符时间，仍应完整读取
+func TestRTUOverTCPMasterWithBaudSplitResponse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		request := make([]byte, 8)
+		if _, err = conn.Read(request); err != nil {
+			return
+		}
+		frame := &common.RTUFrame{SlaveId: 1, PDU: &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReadHoldingRegisters,
+			Data:         []byte{2, 0x00, 0x2A},
+		}}
+		response := frame.ToBytes()
+		_, _ = conn.Write(response[:3])
+		time.Sleep(60 * time.Millisecond)
+		_, _ = conn.Write(response[3:])
+	}()
+
+	m := master.NewModbusRTUOverTCPMasterWithBaud(listener.Addr().String(), 19200)
+	values, err := m.ReadHoldingRegisterValues(1, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != 0x2A {
+		t.Fatalf("value = %v, want %v", values[0], 0x2A)
+	}
+}
@@ -0,0 +1,44 @@
+package master
+
+import (
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// NewModbusRTUOverUDPMasterWithAddress 使用默认的 UDPClient 创建 RTU over UDP 主站，用于以 UDP 透传 RTU 帧的 DTU，
+// DrainTimeout 为 common.DefaultUDPDrainTimeout
+func NewModbusRTUOverUDPMasterWithAddress(address string) *ModbusMaster {
+	udpClient := common.NewUDPClient(address)
+	udpClient.DrainTimeout = common.DefaultUDPDrainTimeout
+	return NewModbusRTUOverUDPMaster(&udpClient)
+}
+
+// NewModbusRTUOverUDPMaster 使用 client 创建 RTU over UDP 主站，RTU 帧没有事务 ID，
+// client.DrainTimeout 为 0 时不丢弃迟到的重复响应，通常应设置为 common.DefaultUDPDrainTimeout
+func NewModbusRTUOverUDPMaster(client *common.UDPClient) *ModbusMaster {
+	message := &common.RTUMessage{}
+	transport := &RTUOverUDPTransport{
+		client: client,
+	}
+	return NewModbusMaster(message, transport)
+}
+
+// RTUOverUDPTransport RTU over UDP 传输定义，每个数据报为一个 RTU 帧，实现 Transport 接口。
+// RTU 帧没有事务 ID，只接受与请求匹配的完整响应，重发后迟到的重复响应由 UDPClient.DrainTimeout 丢弃
+type RTUOverUDPTransport struct {
+	client *common.UDPClient
+	// ResponseLength 计算响应长度的函数，用于内置计算无法确定响应长度的自定义功能码，为 nil 时只使用内置的计算
+	ResponseLength common.ResponseLengthFunc
+}
+
+// Send 发送请求数据报，并返回第一个通过 common.VerifyRTUResponse 校验的响应数据报
+func (t *RTUOverUDPTransport) Send(requestData []byte) (responseData []byte, err error) {
+	return t.client.Send(requestData, func(datagram []byte) bool {
+		return common.VerifyRTUResponse(requestData, datagram, t.ResponseLength) == nil
+	})
+}
+
+// Transmit 发送请求数据报，不等待响应
+func (t *RTUOverUDPTransport) Transmit(requestData []byte) (err error) {
+	_, err = t.client.Send(requestData, nil)
+	return
+}
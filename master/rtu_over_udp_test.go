@@ -0,0 +1,129 @@
+package master_test
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// newRTUOverUDPMaster 创建连接到 address 的 RTU over UDP 主站，等待响应 100ms，不重发
+func newRTUOverUDPMaster(address string, drainTimeout time.Duration) *master.ModbusMaster {
+	client := common.NewUDPClient(address)
+	client.Timeout = 100 * time.Millisecond
+	client.Retries = 0
+	client.DrainTimeout = drainTimeout
+	return master.NewModbusRTUOverUDPMaster(&client)
+}
+
+// TestRTUOverUDPDrainLateResponse 超时请求迟到的响应与下一个请求的响应无法区分，发送下一个请求前被丢弃
+func TestRTUOverUDPDrainLateResponse(t *testing.T) {
+	store := slave.NewMemoryDataStore()
+	store.Write(slave.PointTypeHoldingRegister, 0, 1)
+	s := slave.NewModbusRTUOverUDPSlave(1, &slave.DeviceInfo{}, store)
+	lateSent := make(chan struct{})
+	_, address := startUDPPeer(t, func(index int, request []byte, reply func(datagram []byte)) {
+		response, err := s.Transport.Send(request)
+		if err != nil {
+			return
+		}
+		// 第一个请求的响应在主站超时后到达，之后的响应都在其后发送
+		if index == 0 {
+			go func() {
+				time.Sleep(200 * time.Millisecond)
+				reply(response)
+				close(lateSent)
+			}()
+			return
+		}
+		<-lateSent
+		reply(response)
+	})
+	m := newRTUOverUDPMaster(address, 300*time.Millisecond)
+
+	if _, err := m.ReadHoldingRegisterValues(1, 0, 1); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("first read: got %v, want deadline exceeded", err)
+	}
+	store.Write(slave.PointTypeHoldingRegister, 0, 2)
+	start := time.Now()
+	values, err := m.ReadHoldingRegisterValues(1, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != 2 {
+		t.Fatalf("register = %v, want 2 rather than the late response", values[0])
+	}
+	// 迟到的响应到达后即停止等待，不等满 DrainTimeout
+	if elapsed := time.Since(start); elapsed >= 300*time.Millisecond {
+		t.Fatalf("second read took %v", elapsed)
+	}
+}
+
+// TestRTUOverUDPIgnoresMismatchedResponse 从站地址或功能码与请求不一致的数据报被丢弃，继续等待匹配的响应
+func TestRTUOverUDPIgnoresMismatchedResponse(t *testing.T) {
+	store := slave.NewMemoryDataStore()
+	store.WriteRegisters(slave.PointTypeHoldingRegister, 0, []uint16{7, 8})
+	s := slave.NewModbusRTUOverUDPSlave(1, &slave.DeviceInfo{}, store)
+	mismatched := func(slaveId byte, functionCode byte) []byte {
+		frame, err := common.NewRTUFrame(slaveId, &common.ProtocolDataUnit{FunctionCode: functionCode, Data: []byte{0x04, 0x00, 0x01, 0x00, 0x02}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return frame.ToBytes()
+	}
+	wrongSlave := mismatched(2, common.FuncCodeReadHoldingRegisters)
+	wrongFunction := mismatched(1, common.FuncCodeReadInputRegisters)
+	_, address := startUDPPeer(t, func(index int, request []byte, reply func(datagram []byte)) {
+		reply(wrongSlave)
+		reply(wrongFunction)
+		if response, err := s.Transport.Send(request); err == nil {
+			reply(response)
+		}
+	})
+
+	values, err := newRTUOverUDPMaster(address, 0).ReadHoldingRegisterValues(1, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(values, []uint16{7, 8}) {
+		t.Fatalf("registers = %v, want [7 8]", values)
+	}
+}
+
+// TestRTUOverUDPSlave RTU over UDP 从站注册到 udp:// 地址的 NetServer，按从站地址分发数据报
+func TestRTUOverUDPSlave(t *testing.T) {
+	store1, store2 := slave.NewMemoryDataStore(), slave.NewMemoryDataStore()
+	address := startUDPNetServer(t,
+		&slave.NewModbusRTUOverUDPSlave(1, &slave.DeviceInfo{}, store1).ModbusDevice,
+		&slave.NewModbusRTUOverUDPSlave(2, &slave.DeviceInfo{}, store2).ModbusDevice)
+	m := master.NewModbusRTUOverUDPMasterWithAddress(address)
+
+	if err := m.WriteMultipleRegisterValues(1, 10, []uint16{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.WriteSingleCoil(2, 4, true); err != nil {
+		t.Fatal(err)
+	}
+	if values := store1.ReadRegisters(slave.PointTypeHoldingRegister, 10, 3); !slices.Equal(values, []uint16{1, 2, 3}) {
+		t.Fatalf("slave 1 registers = %v", values)
+	}
+	if v := store2.Read(slave.PointTypeCoil, 4); v != 1 {
+		t.Fatalf("slave 2 coil = %v, want 1", v)
+	}
+	values, err := m.ReadHoldingRegisterValues(1, 10, 3)
+	if err != nil || !slices.Equal(values, []uint16{1, 2, 3}) {
+		t.Fatalf("read slave 1 = %v, %v", values, err)
+	}
+	if values, err = m.ReadHoldingRegisterValues(2, 10, 3); err != nil || !slices.Equal(values, []uint16{0, 0, 0}) {
+		t.Fatalf("read slave 2 = %v, %v", values, err)
+	}
+	var exception *common.Error
+	if _, err = m.Execute(1, &common.ProtocolDataUnit{FunctionCode: 0x41}); !errors.As(err, &exception) || exception.ExceptionCode != common.ExceptionCodeIllegalFunction {
+		t.Fatalf("unknown function code: got %v, want illegal function exception", err)
+	}
+}
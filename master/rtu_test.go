@@ -0,0 +1,68 @@
+//go:build linux
+
+package master_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// TestRTUMasterOverPseudoTerminal 在伪终端的一端运行带两个从站的串口服务，另一端作为主站的串口
+func TestRTUMasterOverPseudoTerminal(t *testing.T) {
+	config := common.SerialConfig{BaudRate: 19200}
+	port, name, err := common.OpenPseudoTerminal(config)
+	if err != nil {
+		t.Skipf("pseudo-terminal is not available: %v", err)
+	}
+	store1, store2 := slave.NewMemoryDataStore(), slave.NewMemoryDataStore()
+	server := common.NewSerialServer(config)
+	server.Enroll(&slave.NewModbusRTUSlave(1, &slave.DeviceInfo{}, store1).ModbusDevice)
+	server.Enroll(&slave.NewModbusRTUSlave(2, &slave.DeviceInfo{}, store2).ModbusDevice)
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(port) }()
+	t.Cleanup(func() {
+		_ = server.Close()
+		if err := <-done; err != nil {
+			t.Errorf("serve: %v", err)
+		}
+	})
+
+	config.Address = name
+	m := master.NewModbusRTUMasterWithConfig(config)
+
+	// 多站总线上按从站地址分别写入 (功能码 0x10) 和读取 (功能码 0x03)
+	for slaveId, values := range map[byte][]uint16{1: {11, 12, 13}, 2: {21, 22, 23}} {
+		if err := m.WriteMultipleRegisterValues(slaveId, 100, values); err != nil {
+			t.Fatalf("slave %v write: %v", slaveId, err)
+		}
+		got, err := m.ReadHoldingRegisterValues(slaveId, 100, uint16(len(values)))
+		if err != nil {
+			t.Fatalf("slave %v read: %v", slaveId, err)
+		}
+		for i := range values {
+			if got[i] != values[i] {
+				t.Fatalf("slave %v read %v, want %v", slaveId, got, values)
+			}
+		}
+	}
+	if v := store1.Read(slave.PointTypeHoldingRegister, 101); v != 12 {
+		t.Errorf("slave 1 store register 101 = %v, want 12", v)
+	}
+	if v := store2.Read(slave.PointTypeHoldingRegister, 101); v != 22 {
+		t.Errorf("slave 2 store register 101 = %v, want 22", v)
+	}
+
+	// 总线上不存在的从站不响应，主站超时
+	if _, err := m.ReadHoldingRegisterValues(3, 100, 1); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("unknown slave: got %v, want timeout", err)
+	}
+	// 超时后总线恢复正常
+	if _, err := m.ReadHoldingRegisterValues(1, 100, 1); err != nil {
+		t.Fatalf("read after timeout: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package master_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// TestReportServerID 服务器 ID 的第二个字节为 0x00 时不能按 1 字节的服务器 ID 和运行指示解析
+func TestReportServerID(t *testing.T) {
+	info := &slave.DeviceInfo{ServerID: []byte{0x12, 0x00}, RunIndicator: true, AdditionalData: []byte("v1")}
+	s := slave.NewModbusTCPSlave(1, info, slave.NewMemoryDataStore())
+	m := master.NewModbusTCPMasterWithAddress(startNetServer(t, &s.ModbusDevice))
+	data := []byte{0x12, 0x00, common.RunIndicatorOn, 'v', '1'}
+
+	check := func(name string, serverID *common.ServerID, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if !bytes.Equal(serverID.ID, info.ServerID) || !serverID.RunIndicator || !bytes.Equal(serverID.AdditionalData, info.AdditionalData) || !bytes.Equal(serverID.Data, data) {
+			t.Fatalf("%v = %+v", name, serverID)
+		}
+	}
+
+	// 未设置长度时只返回原始数据
+	serverID, err := m.ReportServerID(1)
+	if err != nil {
+		t.Fatalf("report server id: %v", err)
+	}
+	if !bytes.Equal(serverID.Data, data) || serverID.ID != nil || serverID.RunIndicator || serverID.AdditionalData != nil {
+		t.Fatalf("report server id = %+v, want only data %v", serverID, data)
+	}
+	serverID, err = common.DecodeServerID(serverID.Data, 2)
+	check("decode server id", serverID, err)
+
+	serverID, err = m.ReportServerIDWithLength(1, 2)
+	check("report server id with length", serverID, err)
+
+	m.SetServerIDLength(1, 2)
+	serverID, err = m.ReportServerID(1)
+	check("report server id with slave length", serverID, err)
+
+	// 长度错误时运行指示的位置不是 0x00 或 0xFF
+	m.SetServerIDLength(1, 3)
+	if _, err = m.ReportServerID(1); err == nil {
+		t.Fatal("report server id with wrong length: got nil error")
+	}
+
+	m.SetServerIDLength(1, -1)
+	if serverID, err = m.ReportServerID(1); err != nil || serverID.ID != nil {
+		t.Fatalf("report server id after removing length = %+v, %v", serverID, err)
+	}
+}
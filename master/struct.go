@@ -0,0 +1,131 @@
+package master
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// ReadStruct 按 v 中字段的 modbus 标签从设备读取，v 必须为结构体指针，标签格式见 common.StructField。
+// 使用默认的 ReadPlanner 将字段合并为尽量少的请求
+func (c *ModbusMaster) ReadStruct(slaveId byte, v any) error {
+	return (&ReadPlanner{}).ReadStruct(c, slaveId, v)
+}
+
+// ReadStruct 按读取计划读取 v 中带 modbus 标签的字段，所在块读取失败的字段保持不变，
+// 返回的 err 汇总所有失败块及解码失败字段的错误
+func (p *ReadPlanner) ReadStruct(c *ModbusMaster, slaveId byte, v any) error {
+	value, err := common.StructValue(v)
+	if err != nil {
+		return err
+	}
+	fields, err := common.ParseStruct(value.Type())
+	if err != nil {
+		return err
+	}
+	items := make([]ReadItem, len(fields))
+	for i, field := range fields {
+		items[i] = ReadItem{FunctionCode: field.FunctionCode, Address: field.Address, Quantity: field.Quantity}
+	}
+	results, err := p.Read(c, slaveId, items)
+	if results == nil {
+		return err
+	}
+	errs := []error{err}
+	for i, field := range fields {
+		result := results[i]
+		if result.Err != nil {
+			continue
+		}
+		var words []uint16
+		if field.IsBit() {
+			words = make([]uint16, len(result.Bits))
+			for j, bit := range result.Bits {
+				if bit {
+					words[j] = 1
+				}
+			}
+		} else {
+			words = result.Values
+		}
+		errs = append(errs, field.Decode(value, words))
+	}
+	return errors.Join(errs...)
+}
+
+// WriteStruct 将 v 中线圈和保持寄存器字段写入设备，v 必须为结构体指针，离散输入和输入寄存器字段被忽略。
+// 地址连续的字段合并为一次写入，合并时不超过设备的块大小，超过块大小的单个字段按块拆分
+func (c *ModbusMaster) WriteStruct(slaveId byte, v any) error {
+	value, err := common.StructValue(v)
+	if err != nil {
+		return err
+	}
+	fields, err := common.ParseStruct(value.Type())
+	if err != nil {
+		return err
+	}
+	limits := c.BlockLimits(slaveId)
+	for _, run := range structWriteRuns(fields, limits) {
+		if err = c.writeStructRun(slaveId, value, run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// structWriteRuns 将可写字段按地址连续分组，每组的点数不超过 limits
+func structWriteRuns(fields []*common.StructField, limits BlockLimits) [][]*common.StructField {
+	var runs [][]*common.StructField
+	var run []*common.StructField
+	var end, quantity int
+	for _, field := range fields {
+		if !field.IsWritable() {
+			continue
+		}
+		limit := int(limits.WriteRegisters)
+		if field.IsBit() {
+			limit = int(limits.WriteBits)
+		}
+		if len(run) > 0 &&
+			run[0].FunctionCode == field.FunctionCode &&
+			int(field.Address) == end &&
+			quantity+int(field.Quantity) <= limit {
+			run = append(run, field)
+			end += int(field.Quantity)
+			quantity += int(field.Quantity)
+			continue
+		}
+		if len(run) > 0 {
+			runs = append(runs, run)
+		}
+		run = []*common.StructField{field}
+		end = int(field.Address) + int(field.Quantity)
+		quantity = int(field.Quantity)
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// writeStructRun 编码一组地址连续的字段并写入
+func (c *ModbusMaster) writeStructRun(slaveId byte, value reflect.Value, run []*common.StructField) error {
+	var words []uint16
+	for _, field := range run {
+		fieldWords, err := field.Encode(value)
+		if err != nil {
+			return err
+		}
+		words = append(words, fieldWords...)
+	}
+	address := run[0].Address
+	if run[0].IsBit() {
+		bits := make([]bool, len(words))
+		for i, word := range words {
+			bits[i] = word != 0
+		}
+		return c.WriteMultipleCoilsRange(slaveId, address, bits)
+	}
+	return c.writeRegistersRange(slaveId, address, words, 1)
+}
@@ -0,0 +1,84 @@
+package master_test
+
+import (
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+type pump struct {
+	Running  bool    `modbus:"coil,0"`
+	Alarm    bool    `modbus:"coil,1"`
+	Fault    bool    `modbus:"di,0"`
+	Speed    float32 `modbus:"hr,0,cdab"`
+	Setpoint int16   `modbus:"hr,2"`
+	Name     string  `modbus:"hr,10,len=4"`
+	Total    uint64  `modbus:"ir,0"`
+}
+
+// TestStructRoundTrip 地址连续的字段合并为一次请求，绑定的结构体随主站写入更新
+func TestStructRoundTrip(t *testing.T) {
+	store := slave.NewMemoryDataStore()
+	var bound pump
+	binding, err := store.Bind(&bound)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Write(slave.PointTypeDiscreteInput, 0, 1)
+	store.WriteRegisters(slave.PointTypeInputRegister, 0, common.EncodeWords([]uint64{1 << 40}, common.ByteOrderABCD))
+	s := slave.NewModbusTCPSlave(1, &slave.DeviceInfo{}, store)
+	m := master.NewModbusTCPMasterWithAddress(startNetServer(t, &s.ModbusDevice))
+	requests := func() uint16 {
+		return s.Handler.Counters().ServerMessageCount
+	}
+
+	written := pump{Running: true, Speed: 12.5, Setpoint: -40, Name: "P-01", Fault: true, Total: 7}
+	if err = m.WriteStruct(1, &written); err != nil {
+		t.Fatal(err)
+	}
+	// 线圈 0-1、保持寄存器 0-2、保持寄存器 10-13
+	if n := requests(); n != 3 {
+		t.Fatalf("write requests = %v, want 3", n)
+	}
+	binding.View(func() {
+		if bound != (pump{Running: true, Speed: 12.5, Setpoint: -40, Name: "P-01", Fault: true, Total: 1 << 40}) {
+			t.Fatalf("bound after write struct = %+v", bound)
+		}
+	})
+
+	var read pump
+	if err = m.ReadStruct(1, &read); err != nil {
+		t.Fatal(err)
+	}
+	// 线圈、离散输入、两段保持寄存器、输入寄存器
+	if n := requests(); n != 8 {
+		t.Fatalf("read requests = %v, want 5", n-3)
+	}
+	if read != (pump{Running: true, Speed: 12.5, Setpoint: -40, Name: "P-01", Fault: true, Total: 1 << 40}) {
+		t.Fatalf("read struct = %+v", read)
+	}
+
+	// FC16 写入覆盖部分字段时，绑定的结构体更新对应字段
+	speed := common.EncodeWords([]float32{-3.5}, common.ByteOrderCDAB)
+	if err = m.WriteMultipleRegisterValues(1, 0, append(speed, 0x0064)); err != nil {
+		t.Fatal(err)
+	}
+	binding.View(func() {
+		if bound.Speed != -3.5 || bound.Setpoint != 100 || bound.Name != "P-01" {
+			t.Fatalf("bound after FC16 = %+v", bound)
+		}
+	})
+
+	// Update 修改的字段写入数据存储，主站可以读取
+	if err = binding.Update(func() { bound.Alarm = true }); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.ReadStruct(1, &read); err != nil {
+		t.Fatal(err)
+	}
+	if !read.Alarm || read.Speed != -3.5 || read.Setpoint != 100 {
+		t.Fatalf("read struct after update = %+v", read)
+	}
+}
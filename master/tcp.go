@@ -40,6 +40,7 @@ func (t *TCPTransport) Send(requestData []byte) (responseData []byte, err error)
 			return e
 		}
 		responseData = frame.ToBytes()
+		frame.Release()
 		return nil
 	})
 	if err != nil {
@@ -47,3 +48,8 @@ func (t *TCPTransport) Send(requestData []byte) (responseData []byte, err error)
 	}
 	return
 }
+
+// Transmit 发送数据到服务器，不等待响应
+func (t *TCPTransport) Transmit(requestData []byte) error {
+	return t.client.Send(requestData, nil)
+}
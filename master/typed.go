@@ -0,0 +1,126 @@
+package master
+
+import (
+	"fmt"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// 按字节序读写多寄存器数值，读取时 functionCode 为 FuncCodeReadHoldingRegisters 或 FuncCodeReadInputRegisters，
+// 数量超过单次请求上限时按设备的块大小拆分，且不会把一个数值拆分到两次请求中
+
+// ReadInt16s 读取 count 个 int16
+func (c *ModbusMaster) ReadInt16s(slaveId byte, functionCode byte, address uint16, count uint16, order common.ByteOrder) ([]int16, error) {
+	return readValues[int16](c, slaveId, functionCode, address, count, order)
+}
+
+// WriteInt16s 写入 int16 到保持寄存器
+func (c *ModbusMaster) WriteInt16s(slaveId byte, address uint16, values []int16, order common.ByteOrder) error {
+	return writeValues(c, slaveId, address, values, order)
+}
+
+// ReadUint32s 读取 count 个 uint32
+func (c *ModbusMaster) ReadUint32s(slaveId byte, functionCode byte, address uint16, count uint16, order common.ByteOrder) ([]uint32, error) {
+	return readValues[uint32](c, slaveId, functionCode, address, count, order)
+}
+
+// WriteUint32s 写入 uint32 到保持寄存器
+func (c *ModbusMaster) WriteUint32s(slaveId byte, address uint16, values []uint32, order common.ByteOrder) error {
+	return writeValues(c, slaveId, address, values, order)
+}
+
+// ReadInt32s 读取 count 个 int32
+func (c *ModbusMaster) ReadInt32s(slaveId byte, functionCode byte, address uint16, count uint16, order common.ByteOrder) ([]int32, error) {
+	return readValues[int32](c, slaveId, functionCode, address, count, order)
+}
+
+// WriteInt32s 写入 int32 到保持寄存器
+func (c *ModbusMaster) WriteInt32s(slaveId byte, address uint16, values []int32, order common.ByteOrder) error {
+	return writeValues(c, slaveId, address, values, order)
+}
+
+// ReadFloat32s 读取 count 个 float32
+func (c *ModbusMaster) ReadFloat32s(slaveId byte, functionCode byte, address uint16, count uint16, order common.ByteOrder) ([]float32, error) {
+	return readValues[float32](c, slaveId, functionCode, address, count, order)
+}
+
+// WriteFloat32s 写入 float32 到保持寄存器
+func (c *ModbusMaster) WriteFloat32s(slaveId byte, address uint16, values []float32, order common.ByteOrder) error {
+	return writeValues(c, slaveId, address, values, order)
+}
+
+// ReadUint64s 读取 count 个 uint64
+func (c *ModbusMaster) ReadUint64s(slaveId byte, functionCode byte, address uint16, count uint16, order common.ByteOrder) ([]uint64, error) {
+	return readValues[uint64](c, slaveId, functionCode, address, count, order)
+}
+
+// WriteUint64s 写入 uint64 到保持寄存器
+func (c *ModbusMaster) WriteUint64s(slaveId byte, address uint16, values []uint64, order common.ByteOrder) error {
+	return writeValues(c, slaveId, address, values, order)
+}
+
+// ReadInt64s 读取 count 个 int64
+func (c *ModbusMaster) ReadInt64s(slaveId byte, functionCode byte, address uint16, count uint16, order common.ByteOrder) ([]int64, error) {
+	return readValues[int64](c, slaveId, functionCode, address, count, order)
+}
+
+// WriteInt64s 写入 int64 到保持寄存器
+func (c *ModbusMaster) WriteInt64s(slaveId byte, address uint16, values []int64, order common.ByteOrder) error {
+	return writeValues(c, slaveId, address, values, order)
+}
+
+// ReadFloat64s 读取 count 个 float64
+func (c *ModbusMaster) ReadFloat64s(slaveId byte, functionCode byte, address uint16, count uint16, order common.ByteOrder) ([]float64, error) {
+	return readValues[float64](c, slaveId, functionCode, address, count, order)
+}
+
+// WriteFloat64s 写入 float64 到保持寄存器
+func (c *ModbusMaster) WriteFloat64s(slaveId byte, address uint16, values []float64, order common.ByteOrder) error {
+	return writeValues(c, slaveId, address, values, order)
+}
+
+// readValues 读取 count 个数值，部分块读取失败时返回已读取的完整数值及 *RangeError
+func readValues[T common.Number](c *ModbusMaster, slaveId byte, functionCode byte, address uint16, count uint16, order common.ByteOrder) ([]T, error) {
+	if err := checkRegisterFunctionCode(functionCode); err != nil {
+		return nil, err
+	}
+	n := common.RegisterCount[T]()
+	quantity := int(count) * n
+	if quantity > 0xFFFF {
+		return nil, fmt.Errorf("modbus: quantity '%v' is out of range [1, %v]", quantity, 0xFFFF)
+	}
+	words, err := c.readRegistersRange(slaveId, functionCode, address, uint16(quantity), n)
+	values, decodeErr := common.DecodeWords[T](words[:len(words)/n*n], order)
+	if err != nil {
+		return values, err
+	}
+	return values, decodeErr
+}
+
+// writeValues 将数值编码后写入保持寄存器
+func writeValues[T common.Number](c *ModbusMaster, slaveId byte, address uint16, values []T, order common.ByteOrder) error {
+	return c.writeRegistersRange(slaveId, address, common.EncodeWords(values, order), common.RegisterCount[T]())
+}
+
+// checkRegisterFunctionCode 检查功能码为 FuncCodeReadHoldingRegisters 或 FuncCodeReadInputRegisters
+func checkRegisterFunctionCode(functionCode byte) error {
+	if functionCode != common.FuncCodeReadHoldingRegisters && functionCode != common.FuncCodeReadInputRegisters {
+		return fmt.Errorf("modbus: function code '%v' is neither '%v' nor '%v'", functionCode, common.FuncCodeReadHoldingRegisters, common.FuncCodeReadInputRegisters)
+	}
+	return nil
+}
+
+// readRegisterBlock 读取一组整体解码的寄存器，如字符串或时间，要求一次读取完成
+func (c *ModbusMaster) readRegisterBlock(slaveId byte, functionCode byte, address uint16, quantity uint16) ([]*common.Register, error) {
+	if err := checkRegisterFunctionCode(functionCode); err != nil {
+		return nil, err
+	}
+	if quantity < 1 || quantity > MaxReadRegisters {
+		return nil, fmt.Errorf("modbus: quantity '%v' is out of range [1, %v]", quantity, MaxReadRegisters)
+	}
+	values := make([]uint16, quantity)
+	if err := c.readRegistersInto(slaveId, functionCode, address, values); err != nil {
+		return nil, err
+	}
+	return common.NewRegistersFromUInt16s(values), nil
+}
@@ -0,0 +1,62 @@
+package master_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// TestReadFloat32sBlockSplit 块大小为奇数时向下取整为 2 的倍数，每个数值完整地在一次请求中读取
+func TestReadFloat32sBlockSplit(t *testing.T) {
+	want := []float32{1, -2.5, 3.25, 100, -0.125, 6, 7.5, 8}
+	store := slave.NewMemoryDataStore()
+	store.WriteRegisters(slave.PointTypeHoldingRegister, 100, common.EncodeWords(want, common.ByteOrderCDAB))
+
+	var mu sync.Mutex
+	var quantities []uint16
+	s := slave.NewModbusTCPSlave(1, &slave.DeviceInfo{}, store)
+	err := s.SetAuthorizer(func(ctx context.Context, request *common.ProtocolDataUnit) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		quantities = append(quantities, binary.BigEndian.Uint16(request.Data[2:]))
+		// 第三块请求失败
+		return len(quantities) != 3
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := master.NewModbusTCPMasterWithAddress(startNetServer(t, &s.ModbusDevice))
+	if err = m.SetBlockLimits(1, master.BlockLimits{ReadRegisters: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := m.ReadFloat32s(1, common.FuncCodeReadHoldingRegisters, 100, 8, common.ByteOrderCDAB)
+	var rangeError *master.RangeError
+	if !errors.As(err, &rangeError) || rangeError.Address != 108 || rangeError.Done != 8 {
+		t.Fatalf("got %v, want range error at address 108 after 8 registers", err)
+	}
+	if !slices.Equal(values, want[:4]) {
+		t.Fatalf("partial values = %v, want %v", values, want[:4])
+	}
+
+	values, err = m.ReadFloat32s(1, common.FuncCodeReadHoldingRegisters, 100, 8, common.ByteOrderCDAB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(values, want) {
+		t.Fatalf("values = %v, want %v", values, want)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	// 失败的读取发送了 3 次请求，完整读取发送了 4 次
+	if !slices.Equal(quantities, []uint16{4, 4, 4, 4, 4, 4, 4}) {
+		t.Fatalf("request quantities = %v, want blocks of 4", quantities)
+	}
+}
@@ -0,0 +1,45 @@
+package master
+
+import (
+	"encoding/binary"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// NewModbusUDPMasterWithAddress 使用默认的 UDPClient 创建 Modbus UDP 主站
+func NewModbusUDPMasterWithAddress(address string) *ModbusMaster {
+	udpClient := common.NewUDPClient(address)
+	return NewModbusUDPMaster(&udpClient)
+}
+
+// NewModbusUDPMaster 创建 Modbus UDP 主站，迟到的响应按事务 ID 丢弃，不需要设置 client.DrainTimeout
+func NewModbusUDPMaster(client *common.UDPClient) *ModbusMaster {
+	message := &common.MBAPMessage{}
+	transport := &UDPTransport{
+		client: client,
+	}
+	return NewModbusMaster(message, transport)
+}
+
+// UDPTransport Modbus UDP 传输定义，每个数据报为一个 MBAP 帧，实现 Transport 接口
+type UDPTransport struct {
+	client *common.UDPClient
+}
+
+// Send 发送请求数据报，只接受事务 ID 与请求一致的完整响应，丢弃之前请求迟到的响应
+func (t *UDPTransport) Send(requestData []byte) (responseData []byte, err error) {
+	transactionId := binary.BigEndian.Uint16(requestData)
+	return t.client.Send(requestData, func(datagram []byte) bool {
+		if len(datagram) < 2 || binary.BigEndian.Uint16(datagram) != transactionId {
+			return false
+		}
+		_, e := common.NewMBAPFrameFromBytes(datagram)
+		return e == nil
+	})
+}
+
+// Transmit 发送请求数据报，不等待响应
+func (t *UDPTransport) Transmit(requestData []byte) (err error) {
+	_, err = t.client.Send(requestData, nil)
+	return
+}
@@ -0,0 +1,243 @@
+package master_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/panjf2000/gnet/v2"
+	"github.com/panjf2000/gnet/v2/pkg/logging"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/master"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// TestNewModbusUDPMasterKeepsClient 创建主站时不修改调用方的 UDPClient
+func TestNewModbusUDPMasterKeepsClient(t *testing.T) {
+	client := common.NewUDPClient("127.0.0.1:502")
+	if client.DrainTimeout != 0 {
+		t.Fatalf("default drain timeout = %v, want 0", client.DrainTimeout)
+	}
+	client.DrainTimeout = 50 * time.Millisecond
+	master.NewModbusUDPMaster(&client)
+	master.NewModbusRTUOverUDPMaster(&client)
+	if client.DrainTimeout != 50*time.Millisecond {
+		t.Fatalf("drain timeout = %v, want %v", client.DrainTimeout, 50*time.Millisecond)
+	}
+}
+
+// udpPeer 本地 UDP 对端，按收到的顺序将请求数据报交给 handle，handle 通过 reply 向来源地址发送响应，可延迟或不发送
+type udpPeer struct {
+	mu       sync.Mutex
+	requests [][]byte
+}
+
+// Requests 返回收到的请求数据报
+func (p *udpPeer) Requests() [][]byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.requests
+}
+
+// startUDPPeer 在本地空闲端口启动 UDP 对端，返回对端和监听地址
+func startUDPPeer(t *testing.T, handle func(index int, request []byte, reply func(datagram []byte))) (*udpPeer, string) {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	peer := &udpPeer{}
+	go func() {
+		buffer := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFromUDP(buffer)
+			if err != nil {
+				return
+			}
+			request := append([]byte(nil), buffer[:n]...)
+			peer.mu.Lock()
+			index := len(peer.requests)
+			peer.requests = append(peer.requests, request)
+			peer.mu.Unlock()
+			handle(index, request, func(datagram []byte) { _, _ = conn.WriteToUDP(datagram, addr) })
+		}
+	}()
+	return peer, conn.LocalAddr().String()
+}
+
+// startUDPNetServer 在本地空闲端口以 udp:// 地址启动注册了 devices 的 NetServer，返回监听地址
+func startUDPNetServer(t *testing.T, devices ...*common.ModbusDevice) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := conn.LocalAddr().String()
+	_ = conn.Close()
+
+	server := common.NewNetServer()
+	for _, device := range devices {
+		server.Enroll(device)
+	}
+	protoAddr := "udp://" + address
+	go func() { _ = gnet.Run(server, protoAddr, gnet.WithLogLevel(logging.ErrorLevel)) }()
+	t.Cleanup(func() { _ = gnet.Stop(context.Background(), protoAddr) })
+
+	// 服务器绑定地址后无法再监听同一地址
+	for i := 0; i < 50; i++ {
+		conn, err := net.ListenPacket("udp", address)
+		if err != nil {
+			return address
+		}
+		_ = conn.Close()
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal(fmt.Errorf("net server '%v' did not start", address))
+	return ""
+}
+
+// newUDPMaster 创建连接到 address 的 Modbus UDP 主站，等待响应 100ms，重发 retries 次
+func newUDPMaster(address string, retries int) *master.ModbusMaster {
+	client := common.NewUDPClient(address)
+	client.Timeout = 100 * time.Millisecond
+	client.Retries = retries
+	return master.NewModbusUDPMaster(&client)
+}
+
+// TestUDPRetransmission 请求数据报丢失时重发相同的请求
+func TestUDPRetransmission(t *testing.T) {
+	store := slave.NewMemoryDataStore()
+	store.Write(slave.PointTypeHoldingRegister, 5, 0x1234)
+	s := slave.NewModbusTCPSlave(1, &slave.DeviceInfo{}, store)
+	peer, address := startUDPPeer(t, func(index int, request []byte, reply func(datagram []byte)) {
+		// 丢弃第一个请求
+		if index == 0 {
+			return
+		}
+		if response, err := s.Transport.Send(request); err == nil {
+			reply(response)
+		}
+	})
+
+	values, err := newUDPMaster(address, 2).ReadHoldingRegisterValues(1, 5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != 0x1234 {
+		t.Fatalf("register = %#x, want 0x1234", values[0])
+	}
+	requests := peer.Requests()
+	if len(requests) != 2 || string(requests[0]) != string(requests[1]) {
+		t.Fatalf("requests = % x, want the same request twice", requests)
+	}
+
+	// 重发次数用完后返回超时错误
+	_, address = startUDPPeer(t, func(int, []byte, func([]byte)) {})
+	if _, err = newUDPMaster(address, 1).ReadHoldingRegisterValues(1, 5, 1); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("got %v, want deadline exceeded", err)
+	}
+}
+
+// TestUDPStaleTransactionId 之前的请求迟到的响应事务 ID 不同，被丢弃
+func TestUDPStaleTransactionId(t *testing.T) {
+	store := slave.NewMemoryDataStore()
+	store.WriteRegisters(slave.PointTypeHoldingRegister, 0, []uint16{0xAAAA, 0xBBBB})
+	s := slave.NewModbusTCPSlave(1, &slave.DeviceInfo{}, store)
+	var late []byte
+	peer, address := startUDPPeer(t, func(index int, request []byte, reply func(datagram []byte)) {
+		response, err := s.Transport.Send(request)
+		if err != nil {
+			return
+		}
+		// 第一个请求的响应在下一个请求到达时才发送，先于下一个请求的响应
+		if index == 0 {
+			late = response
+			return
+		}
+		reply(late)
+		reply(response)
+	})
+	m := newUDPMaster(address, 0)
+
+	if _, err := m.ReadHoldingRegisterValues(1, 0, 1); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("first read: got %v, want deadline exceeded", err)
+	}
+	values, err := m.ReadHoldingRegisterValues(1, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != 0xBBBB {
+		t.Fatalf("register = %#x, want 0xbbbb", values[0])
+	}
+	requests := peer.Requests()
+	if binary.BigEndian.Uint16(requests[0]) == binary.BigEndian.Uint16(requests[1]) {
+		t.Fatalf("requests share transaction id %v", binary.BigEndian.Uint16(requests[0]))
+	}
+}
+
+// TestNetServerDatagramReplyAddress NetServer 将响应发送到每个数据报的来源地址
+func TestNetServerDatagramReplyAddress(t *testing.T) {
+	store := slave.NewMemoryDataStore()
+	store.WriteRegisters(slave.PointTypeHoldingRegister, 0, []uint16{10, 20})
+	address := startUDPNetServer(t, &slave.NewModbusTCPSlave(1, &slave.DeviceInfo{}, store).ModbusDevice)
+
+	serverAddr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conns := make([]*net.UDPConn, 2)
+	for i := range conns {
+		if conns[i], err = net.DialUDP("udp", nil, serverAddr); err != nil {
+			t.Fatal(err)
+		}
+		defer conns[i].Close()
+	}
+	// 两个客户端交替发送请求，每个客户端只收到自己请求的响应
+	for round := 0; round < 3; round++ {
+		for i, conn := range conns {
+			request := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadHoldingRegisters}
+			request.LoadData(uint16(i), 1)
+			if _, err = conn.Write(common.NewMBAPFrame(uint16(round*10+i), 1, request).ToBytes()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for i, conn := range conns {
+			_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+			buffer := make([]byte, 256)
+			n, err := conn.Read(buffer)
+			if err != nil {
+				t.Fatalf("client %v round %v: %v", i, round, err)
+			}
+			frame, err := common.NewMBAPFrameFromBytes(buffer[:n])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if frame.TransactionId != uint16(round*10+i) || binary.BigEndian.Uint16(frame.PDU.Data[1:]) != uint16(10*(i+1)) {
+				t.Fatalf("client %v round %v: response transaction %v data % x", i, round, frame.TransactionId, frame.PDU.Data)
+			}
+		}
+	}
+	for i, conn := range conns {
+		_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		if n, err := conn.Read(make([]byte, 256)); !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("client %v: unexpected datagram of %v bytes, %v", i, n, err)
+		}
+	}
+
+	// Modbus UDP 主站读写
+	m := master.NewModbusUDPMasterWithAddress(address)
+	if err = m.WriteSingleRegister(1, 1, 30); err != nil {
+		t.Fatal(err)
+	}
+	if values, err := m.ReadHoldingRegisterValues(1, 0, 2); err != nil || values[1] != 30 {
+		t.Fatalf("registers = %v, %v", values, err)
+	}
+}
@@ -0,0 +1,48 @@
+package slave
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// NewModbusASCIISlave 创建 ASCII 从站，可注册到 common.NetServer（ASCII over TCP）或 common.SerialServer
+func NewModbusASCIISlave(slaveId uint8, deviceInfo *DeviceInfo, store *MemoryDataStore) *ModbusSlave {
+	transport := &ASCIITransport{}
+	transport.RequestHandler.store = store
+	transport.RequestHandler.DeviceInfo = deviceInfo
+	slaveInfo := common.ModbusDevice{
+		SlaveId:   slaveId,
+		FrameType: common.FrameTypeASCII,
+		Transport: transport,
+	}
+	return NewModbusSlave(slaveInfo, deviceInfo, store)
+}
+
+type ASCIITransport struct {
+	RequestHandler
+}
+
+func (t *ASCIITransport) Send(requestData []byte) (responseData []byte, err error) {
+	frame, err := common.NewASCIIFrameFromBytes(requestData)
+	if err != nil {
+		t.countBusMessage(true)
+		return nil, err
+	}
+	// 结束符不是本设备的结束符时，帧在本设备看来没有结束，不处理
+	if delimiter := t.ASCIIInputDelimiter(); requestData[len(requestData)-1] != delimiter {
+		t.countBusMessage(true)
+		return nil, fmt.Errorf("modbus: message delimiter '%q' does not match '%q'", requestData[len(requestData)-1], delimiter)
+	}
+	t.countBusMessage(false)
+	if frame.SlaveId == common.BroadcastSlaveId {
+		return nil, t.HandleBroadcast(context.Background(), frame.PDU)
+	}
+	response, err := t.HandleRequest(frame.PDU)
+	if err != nil || response == nil {
+		return nil, err
+	}
+	frame.PDU = response
+	return frame.ToBytes(), nil
+}
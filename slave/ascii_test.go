@@ -0,0 +1,61 @@
+package slave_test
+
+import (
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// asciiRequest 编码 ASCII 请求帧，并将结束符 LF 替换为 delimiter
+func asciiRequest(t *testing.T, pdu *common.ProtocolDataUnit, delimiter byte) []byte {
+	t.Helper()
+	frame, err := common.NewASCIIFrame(1, pdu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := frame.ToBytes()
+	data[len(data)-1] = delimiter
+	return data
+}
+
+// TestASCIIChangeInputDelimiter 诊断子功能 0x03 修改结束符后，只处理以新结束符结束的帧
+func TestASCIIChangeInputDelimiter(t *testing.T) {
+	s := slave.NewModbusASCIISlave(1, &slave.DeviceInfo{}, slave.NewMemoryDataStore())
+	read := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadHoldingRegisters, Data: []byte{0x00, 0x00, 0x00, 0x01}}
+
+	if _, err := s.Transport.Send(asciiRequest(t, read, '#')); err == nil {
+		t.Fatal("'#' before the delimiter is changed: got nil error")
+	}
+	change := &common.ProtocolDataUnit{FunctionCode: common.FuncCodeDiagnostics}
+	change.LoadData(common.DiagnosticChangeASCIIInputDelimiter, uint16('#')<<8)
+	responseData, err := s.Transport.Send(asciiRequest(t, change, '\n'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response, err := common.NewASCIIFrameFromBytes(responseData); err != nil || response.PDU.FunctionCode != common.FuncCodeDiagnostics {
+		t.Fatalf("change delimiter response = %q, %v", responseData, err)
+	}
+	if delimiter := s.Transport.(common.ASCIIDelimiterTransport).ASCIIInputDelimiter(); delimiter != '#' {
+		t.Fatalf("input delimiter = %q, want '#'", delimiter)
+	}
+
+	if _, err = s.Transport.Send(asciiRequest(t, read, '\n')); err == nil {
+		t.Fatal("LF after the delimiter is changed: got nil error")
+	}
+	responseData, err = s.Transport.Send(asciiRequest(t, read, '#'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := common.NewASCIIFrameFromBytes(responseData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.PDU.FunctionCode != common.FuncCodeReadHoldingRegisters {
+		t.Fatalf("read response function code = %v", response.PDU.FunctionCode)
+	}
+	// 响应仍以 CRLF 结束
+	if responseData[len(responseData)-1] != '\n' {
+		t.Fatalf("response delimiter = %q, want LF", responseData[len(responseData)-1])
+	}
+}
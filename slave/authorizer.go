@@ -0,0 +1,26 @@
+package slave
+
+import (
+	"context"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// Authorizer 请求授权函数，返回 false 时以非法功能 (0x01) 异常响应，即 Modbus/TCP Security 中未授权请求的响应。
+// 通过 common.TLSServer 接入时可使用 common.RoleFromContext 读取客户端证书中的 Modbus Role
+type Authorizer func(ctx context.Context, request *common.ProtocolDataUnit) bool
+
+// SetAuthorizer 设置请求授权函数，authorizer 为 nil 时不做授权检查
+func (s *RequestHandler) SetAuthorizer(authorizer Authorizer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authorizer = authorizer
+}
+
+// authorize 检查请求是否被授权，未设置授权函数时均被授权
+func (s *RequestHandler) authorize(ctx context.Context, request *common.ProtocolDataUnit) bool {
+	s.mu.RLock()
+	authorizer := s.authorizer
+	s.mu.RUnlock()
+	return authorizer == nil || authorizer(ctx, request)
+}
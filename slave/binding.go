@@ -0,0 +1,128 @@
+package slave
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// Binding 绑定到 MemoryDataStore 的结构体，对绑定地址的写入会同步更新结构体字段
+type Binding struct {
+	mu     sync.Mutex
+	store  *MemoryDataStore
+	value  reflect.Value
+	fields []*common.StructField
+}
+
+// Bind 按字段的 modbus 标签将结构体指针 v 绑定到数据存储，标签格式见 common.StructField。
+// 绑定时先将字段的当前值写入数据存储，此后主站请求或 Write 等方法对绑定地址的写入会更新对应字段，
+// 无法解码到字段的值（如超出字段类型范围）被忽略。应用应通过 View 和 Update 访问字段
+func (m *MemoryDataStore) Bind(v any) (*Binding, error) {
+	value, err := common.StructValue(v)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := common.ParseStruct(value.Type())
+	if err != nil {
+		return nil, err
+	}
+	binding := &Binding{
+		store:  m,
+		value:  value,
+		fields: fields,
+	}
+	if err = binding.sync(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.bindings = append(m.bindings, binding)
+	m.mu.Unlock()
+	return binding, nil
+}
+
+// Unbind 解除结构体绑定，数据存储中的值保持不变
+func (m *MemoryDataStore) Unbind(binding *Binding) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, b := range m.bindings {
+		if b == binding {
+			m.bindings = append(m.bindings[:i], m.bindings[i+1:]...)
+			break
+		}
+	}
+}
+
+// View 在绑定的锁内执行 fn，用于读取结构体字段
+func (b *Binding) View(fn func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fn()
+}
+
+// Update 在绑定的锁内执行 fn 修改结构体字段，然后将全部字段写入数据存储
+func (b *Binding) Update(fn func()) error {
+	b.mu.Lock()
+	fn()
+	b.mu.Unlock()
+	return b.sync()
+}
+
+// sync 将全部字段编码后写入数据存储，写入在绑定的锁外进行，避免写事件回到 update 时死锁
+func (b *Binding) sync() error {
+	b.mu.Lock()
+	values := make([][]uint16, len(b.fields))
+	for i, field := range b.fields {
+		words, err := field.Encode(b.value)
+		if err != nil {
+			b.mu.Unlock()
+			return err
+		}
+		values[i] = words
+	}
+	b.mu.Unlock()
+
+	for i, field := range b.fields {
+		pointType := structPointType(field.FunctionCode)
+		if field.IsBit() {
+			b.store.Write(pointType, field.Address, values[i][0])
+			continue
+		}
+		b.store.WriteRegisters(pointType, field.Address, values[i])
+	}
+	return nil
+}
+
+// update 写事件发生后，从数据存储重新读取并解码覆盖该地址的字段
+func (b *Binding) update(event Point) {
+	for _, field := range b.fields {
+		if structPointType(field.FunctionCode) != event.Type ||
+			event.Address < field.Address || int(event.Address) >= int(field.Address)+int(field.Quantity) {
+			continue
+		}
+		var words []uint16
+		if field.IsBit() {
+			words = []uint16{b.store.Read(event.Type, field.Address)}
+		} else {
+			words = b.store.ReadRegisters(event.Type, field.Address, field.Quantity)
+		}
+		b.mu.Lock()
+		_ = field.Decode(b.value, words)
+		b.mu.Unlock()
+	}
+}
+
+// structPointType 返回字段区域对应的点类型
+func structPointType(functionCode byte) PointType {
+	switch functionCode {
+	case common.FuncCodeReadCoils:
+		return PointTypeCoil
+	case common.FuncCodeReadDiscreteInputs:
+		return PointTypeDiscreteInput
+	case common.FuncCodeReadHoldingRegisters:
+		return PointTypeHoldingRegister
+	}
+	return PointTypeInputRegister
+}
@@ -0,0 +1,42 @@
+package slave
+
+import (
+	"context"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// CANopenHandler CANopen General Reference (MEI 类型 0x0D) 的处理函数，request 与返回值均为不含 MEI 类型的 CANopen 数据，
+// 错误的处理同 FunctionHandler
+type CANopenHandler func(ctx context.Context, request []byte) ([]byte, error)
+
+// SetCANopenHandler 设置 CANopen General Reference 的处理函数，handler 为 nil 时不支持该请求
+func (s *RequestHandler) SetCANopenHandler(handler CANopenHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.canopen = handler
+}
+
+// handleCANopenGeneralReference 处理 CANopen General Reference 请求 (功能码 0x2B, MEI 类型 0x0D)
+func (s *RequestHandler) handleCANopenGeneralReference(ctx context.Context, request *common.ProtocolDataUnit) *common.ProtocolDataUnit {
+	s.mu.RLock()
+	handler := s.canopen
+	s.mu.RUnlock()
+	if handler == nil {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReadDeviceIdentification | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalFunction},
+		}
+	}
+	result, err := handler(ctx, request.Data[1:])
+	if err != nil {
+		return errorResponse(request.FunctionCode, err)
+	}
+	responseData := make([]byte, 1+len(result))
+	responseData[0] = common.MEITypeCANopenGeneralReference
+	copy(responseData[1:], result)
+	return &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeReadDeviceIdentification,
+		Data:         responseData,
+	}
+}
@@ -0,0 +1,147 @@
+package slave
+
+import (
+	"encoding/binary"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// commEventLogSize 通信事件日志最多保留的事件数量
+const commEventLogSize = 64
+
+// commEventLog 通信事件日志环形缓冲区
+type commEventLog struct {
+	events [commEventLogSize]byte
+	head   int // 下一个写入位置
+	count  int
+}
+
+// push 记录一个事件，缓冲区满时覆盖最旧的事件
+func (l *commEventLog) push(event byte) {
+	l.events[l.head] = event
+	l.head = (l.head + 1) % commEventLogSize
+	if l.count < commEventLogSize {
+		l.count++
+	}
+}
+
+// snapshot 返回日志中的全部事件，最新的在前
+func (l *commEventLog) snapshot() []byte {
+	events := make([]byte, l.count)
+	for i := 0; i < l.count; i++ {
+		events[i] = l.events[(l.head-1-i+commEventLogSize)%commEventLogSize]
+	}
+	return events
+}
+
+// clear 清空日志
+func (l *commEventLog) clear() {
+	l.head = 0
+	l.count = 0
+}
+
+// ExceptionStatus 返回异常状态，即 Read Exception Status 返回的 8 个状态位
+func (s *RequestHandler) ExceptionStatus() byte {
+	s.diagnostics.mu.Lock()
+	defer s.diagnostics.mu.Unlock()
+	return s.diagnostics.exceptionStatus
+}
+
+// SetExceptionStatus 设置异常状态，各状态位的含义由应用自行定义
+func (s *RequestHandler) SetExceptionStatus(status byte) {
+	s.diagnostics.mu.Lock()
+	defer s.diagnostics.mu.Unlock()
+	s.diagnostics.exceptionStatus = status
+}
+
+// SetBusy 设置 Get Comm Event Counter/Log 返回的状态字，busy 为 true 时状态字为 0xFFFF
+func (s *RequestHandler) SetBusy(busy bool) {
+	s.diagnostics.mu.Lock()
+	defer s.diagnostics.mu.Unlock()
+	s.diagnostics.busy = busy
+}
+
+// CommEventLog 返回通信事件日志的快照
+func (s *RequestHandler) CommEventLog() *common.CommEventLog {
+	s.diagnostics.mu.Lock()
+	defer s.diagnostics.mu.Unlock()
+	return s.diagnostics.commEventLog()
+}
+
+// commEventLog 生成通信事件日志，调用方需持有锁
+func (d *diagnostics) commEventLog() *common.CommEventLog {
+	log := &common.CommEventLog{
+		EventCount:   d.eventCounter,
+		MessageCount: d.counters.BusMessageCount,
+		Events:       d.eventLog.snapshot(),
+	}
+	if d.busy {
+		log.Status = common.CommEventStatusBusy
+	}
+	return log
+}
+
+// sendEvent 根据响应生成发送事件
+func sendEvent(response *common.ProtocolDataUnit, listenOnly bool) byte {
+	event := common.CommEventSend
+	if listenOnly {
+		event |= common.CommEventSendListenOnly
+	}
+	if response.FunctionCode&0x80 == 0 || len(response.Data) == 0 {
+		return event
+	}
+	switch response.Data[0] {
+	case common.ExceptionCodeIllegalFunction,
+		common.ExceptionCodeIllegalDataAddress,
+		common.ExceptionCodeIllegalDataValue:
+		event |= common.CommEventSendReadException
+	case common.ExceptionCodeServerDeviceFailure:
+		event |= common.CommEventSendServerAbortException
+	case common.ExceptionCodeAcknowledge,
+		common.ExceptionCodeServerDeviceBusy:
+		event |= common.CommEventSendServerBusyException
+	case common.ExceptionCodeNegativeAcknowledge:
+		event |= common.CommEventSendServerNAKException
+	}
+	return event
+}
+
+// handleReadExceptionStatus 处理读取异常状态请求 (功能码 0x07)
+func (s *RequestHandler) handleReadExceptionStatus(request *common.ProtocolDataUnit) *common.ProtocolDataUnit {
+	return &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeReadExceptionStatus,
+		Data:         []byte{s.ExceptionStatus()},
+	}
+}
+
+// handleGetCommEventCounter 处理读取通信事件计数请求 (功能码 0x0B)
+func (s *RequestHandler) handleGetCommEventCounter(request *common.ProtocolDataUnit) *common.ProtocolDataUnit {
+	log := s.CommEventLog()
+
+	responseData := make([]byte, 4)
+	binary.BigEndian.PutUint16(responseData[0:2], log.Status)
+	binary.BigEndian.PutUint16(responseData[2:4], log.EventCount)
+
+	return &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeGetCommEventCounter,
+		Data:         responseData,
+	}
+}
+
+// handleGetCommEventLog 处理读取通信事件日志请求 (功能码 0x0C)
+func (s *RequestHandler) handleGetCommEventLog(request *common.ProtocolDataUnit) *common.ProtocolDataUnit {
+	log := s.CommEventLog()
+
+	// 构建响应: [字节数] [状态字(2)] [事件计数(2)] [报文计数(2)] [事件...]
+	responseData := make([]byte, 7+len(log.Events))
+	responseData[0] = byte(6 + len(log.Events))
+	binary.BigEndian.PutUint16(responseData[1:3], log.Status)
+	binary.BigEndian.PutUint16(responseData[3:5], log.EventCount)
+	binary.BigEndian.PutUint16(responseData[5:7], log.MessageCount)
+	copy(responseData[7:], log.Events)
+
+	return &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeGetCommEventLog,
+		Data:         responseData,
+	}
+}
@@ -0,0 +1,253 @@
+package slave
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// DiagnosticCounters 串行链路诊断计数器，由 Diagnostics (功能码 0x08) 的子功能读取
+type DiagnosticCounters struct {
+	BusMessageCount            uint16 // 总线上检测到的报文数量
+	BusCommunicationErrorCount uint16 // CRC 错误的报文数量
+	BusExceptionErrorCount     uint16 // 返回的异常响应数量
+	ServerMessageCount         uint16 // 发往本从站（含广播）并已处理的报文数量
+	ServerNoResponseCount      uint16 // 未返回响应的报文数量
+	ServerNAKCount             uint16 // 返回否定确认异常的数量
+	ServerBusyCount            uint16 // 返回从站忙异常的数量
+	BusCharacterOverrunCount   uint16 // 字符溢出的报文数量
+}
+
+// diagnostics 从站诊断状态，零值可用
+type diagnostics struct {
+	mu             sync.Mutex
+	counters       DiagnosticCounters
+	register       uint16
+	listenOnly     bool
+	asciiDelimiter byte // ASCII 模式的结束符，零值表示 LF
+
+	eventLog        commEventLog
+	eventCounter    uint16 // 成功完成的请求数量，不含 Get Comm Event Counter/Log 本身
+	exceptionStatus byte
+	busy            bool
+}
+
+// Counters 返回诊断计数器的快照
+func (s *RequestHandler) Counters() DiagnosticCounters {
+	s.diagnostics.mu.Lock()
+	defer s.diagnostics.mu.Unlock()
+	return s.diagnostics.counters
+}
+
+// DiagnosticRegister 返回诊断寄存器的值
+func (s *RequestHandler) DiagnosticRegister() uint16 {
+	s.diagnostics.mu.Lock()
+	defer s.diagnostics.mu.Unlock()
+	return s.diagnostics.register
+}
+
+// SetDiagnosticRegister 设置诊断寄存器的值，内容由应用自行定义
+func (s *RequestHandler) SetDiagnosticRegister(value uint16) {
+	s.diagnostics.mu.Lock()
+	defer s.diagnostics.mu.Unlock()
+	s.diagnostics.register = value
+}
+
+// ListenOnly 是否处于仅监听模式
+func (s *RequestHandler) ListenOnly() bool {
+	s.diagnostics.mu.Lock()
+	defer s.diagnostics.mu.Unlock()
+	return s.diagnostics.listenOnly
+}
+
+// ASCIIInputDelimiter 返回 ASCII 模式的结束符，默认为 LF
+func (s *RequestHandler) ASCIIInputDelimiter() byte {
+	s.diagnostics.mu.Lock()
+	defer s.diagnostics.mu.Unlock()
+	if s.diagnostics.asciiDelimiter == 0 {
+		return '\n'
+	}
+	return s.diagnostics.asciiDelimiter
+}
+
+// countBusMessage 记录一条总线报文，commError 表示报文校验失败
+func (s *RequestHandler) countBusMessage(commError bool) {
+	s.diagnostics.mu.Lock()
+	defer s.diagnostics.mu.Unlock()
+	if commError {
+		s.diagnostics.counters.BusCommunicationErrorCount++
+		s.diagnostics.eventLog.push(common.CommEventReceive | common.CommEventReceiveCommunicationError)
+		return
+	}
+	s.diagnostics.counters.BusMessageCount++
+}
+
+// logReceive 在通信事件日志中记录一个接收事件，broadcast 表示广播请求
+func (s *RequestHandler) logReceive(broadcast bool) {
+	s.diagnostics.mu.Lock()
+	defer s.diagnostics.mu.Unlock()
+	event := common.CommEventReceive
+	if broadcast {
+		event |= common.CommEventReceiveBroadcast
+	}
+	if s.diagnostics.listenOnly {
+		event |= common.CommEventReceiveListenOnly
+	}
+	s.diagnostics.eventLog.push(event)
+}
+
+// countResponse 根据处理结果更新计数器和通信事件日志，response 为 nil 表示未返回响应
+func (s *RequestHandler) countResponse(request, response *common.ProtocolDataUnit) {
+	s.diagnostics.mu.Lock()
+	defer s.diagnostics.mu.Unlock()
+
+	counters := &s.diagnostics.counters
+	counters.ServerMessageCount++
+	if response == nil {
+		counters.ServerNoResponseCount++
+		return
+	}
+	s.diagnostics.eventLog.push(sendEvent(response, s.diagnostics.listenOnly))
+	if response.FunctionCode&0x80 == 0 || len(response.Data) == 0 {
+		switch request.FunctionCode {
+		case common.FuncCodeGetCommEventCounter, common.FuncCodeGetCommEventLog:
+		default:
+			s.diagnostics.eventCounter++
+		}
+		return
+	}
+	counters.BusExceptionErrorCount++
+	switch response.Data[0] {
+	case common.ExceptionCodeNegativeAcknowledge:
+		counters.ServerNAKCount++
+	case common.ExceptionCodeServerDeviceBusy:
+		counters.ServerBusyCount++
+	}
+}
+
+// handleDiagnostics 处理诊断请求 (功能码 0x08)，返回 nil 表示不响应
+func (s *RequestHandler) handleDiagnostics(request *common.ProtocolDataUnit) *common.ProtocolDataUnit {
+	if len(request.Data) < 2 {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeDiagnostics | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataValue},
+		}
+	}
+
+	subFunction := binary.BigEndian.Uint16(request.Data[0:2])
+	data := request.Data[2:]
+	if subFunction == common.DiagnosticReturnQueryData {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeDiagnostics,
+			Data:         request.Data,
+		}
+	}
+	if len(data) != 2 {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeDiagnostics | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataValue},
+		}
+	}
+	value := binary.BigEndian.Uint16(data)
+
+	d := &s.diagnostics
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var result uint16
+	switch subFunction {
+	case common.DiagnosticRestartCommunicationsOption:
+		if value != 0x0000 && value != 0xFF00 {
+			return &common.ProtocolDataUnit{
+				FunctionCode: common.FuncCodeDiagnostics | 0x80,
+				Data:         []byte{common.ExceptionCodeIllegalDataValue},
+			}
+		}
+		// 处于仅监听模式时不返回响应
+		listenOnly := d.listenOnly
+		d.listenOnly = false
+		d.counters = DiagnosticCounters{}
+		d.eventCounter = 0
+		if value == 0xFF00 {
+			d.eventLog.clear()
+		}
+		d.eventLog.push(common.CommEventCommunicationRestart)
+		if listenOnly {
+			return nil
+		}
+		result = value
+	case common.DiagnosticReturnDiagnosticRegister:
+		result = d.register
+	case common.DiagnosticChangeASCIIInputDelimiter:
+		delimiter := byte(value >> 8)
+		if !validASCIIDelimiter(delimiter) {
+			return &common.ProtocolDataUnit{
+				FunctionCode: common.FuncCodeDiagnostics | 0x80,
+				Data:         []byte{common.ExceptionCodeIllegalDataValue},
+			}
+		}
+		d.asciiDelimiter = delimiter
+		result = value
+	case common.DiagnosticForceListenOnlyMode:
+		d.listenOnly = true
+		d.eventLog.push(common.CommEventEnteredListenOnly)
+		return nil
+	case common.DiagnosticClearCountersAndDiagnosticRegister:
+		d.counters = DiagnosticCounters{}
+		d.register = 0
+		d.eventCounter = 0
+		result = value
+	case common.DiagnosticReturnBusMessageCount:
+		result = d.counters.BusMessageCount
+	case common.DiagnosticReturnBusCommunicationErrorCount:
+		result = d.counters.BusCommunicationErrorCount
+	case common.DiagnosticReturnBusExceptionErrorCount:
+		result = d.counters.BusExceptionErrorCount
+	case common.DiagnosticReturnServerMessageCount:
+		result = d.counters.ServerMessageCount
+	case common.DiagnosticReturnServerNoResponseCount:
+		result = d.counters.ServerNoResponseCount
+	case common.DiagnosticReturnServerNAKCount:
+		result = d.counters.ServerNAKCount
+	case common.DiagnosticReturnServerBusyCount:
+		result = d.counters.ServerBusyCount
+	case common.DiagnosticReturnBusCharacterOverrunCount:
+		result = d.counters.BusCharacterOverrunCount
+	case common.DiagnosticClearOverrunCounterAndFlag:
+		d.counters.BusCharacterOverrunCount = 0
+		result = value
+	default:
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeDiagnostics | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalFunction},
+		}
+	}
+
+	responseData := make([]byte, 4)
+	binary.BigEndian.PutUint16(responseData[0:2], subFunction)
+	binary.BigEndian.PutUint16(responseData[2:4], result)
+
+	return &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeDiagnostics,
+		Data:         responseData,
+	}
+}
+
+// validASCIIDelimiter 判断字符能否作为 ASCII 帧的结束符，帧起始符、CR、十六进制字符和 0 不能作为结束符
+func validASCIIDelimiter(delimiter byte) bool {
+	switch {
+	case delimiter == 0, delimiter == ':', delimiter == '\r':
+		return false
+	case delimiter >= '0' && delimiter <= '9', delimiter >= 'A' && delimiter <= 'F', delimiter >= 'a' && delimiter <= 'f':
+		return false
+	}
+	return true
+}
+
+// isRestartCommunicationsOption 判断请求是否为重启通信选项，仅监听模式下只响应该请求
+func isRestartCommunicationsOption(request *common.ProtocolDataUnit) bool {
+	return request.FunctionCode == common.FuncCodeDiagnostics &&
+		len(request.Data) >= 2 &&
+		binary.BigEndian.Uint16(request.Data) == common.DiagnosticRestartCommunicationsOption
+}
@@ -0,0 +1,142 @@
+package slave
+
+import (
+	"encoding/binary"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// SetEnronLayout 设置 Enron/Daniel 寄存器布局，layout 为 nil 时所有保持寄存器均为 16 位。
+// 落在 32 位区间内的保持寄存器单独存储，通过 ReadEnronRegisters 和 WriteEnronRegisters 访问
+func (m *MemoryDataStore) SetEnronLayout(layout *common.EnronLayout) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.enronLayout = layout
+}
+
+// EnronLayout 返回 Enron/Daniel 寄存器布局，未设置时为 nil
+func (m *MemoryDataStore) EnronLayout() *common.EnronLayout {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.enronLayout
+}
+
+// ReadEnronRegisters 在同一把锁内读取连续的 32 位寄存器
+func (m *MemoryDataStore) ReadEnronRegisters(address uint16, quantity uint16) []uint32 {
+	values := make([]uint32, quantity)
+	m.mu.RLock()
+	for i := range values {
+		values[i] = m.enronRegisters[address+uint16(i)]
+	}
+	m.mu.RUnlock()
+	return values
+}
+
+// WriteEnronRegisters 在同一把锁内写入连续的 32 位寄存器，写入后逐个触发写事件
+func (m *MemoryDataStore) WriteEnronRegisters(address uint16, values []uint32) {
+	m.mu.Lock()
+	for i, value := range values {
+		m.enronRegisters[address+uint16(i)] = value
+	}
+	m.mu.Unlock()
+	for i, value := range values {
+		m.triggerPointEvent(Point{
+			Address: address + uint16(i),
+			Value:   uint16(value),
+			Value32: value,
+			Type:    PointTypeEnronRegister,
+		})
+	}
+}
+
+// enronWidth 返回请求的寄存器宽度，跨越 32 位区间边界时返回非法数据地址异常
+func (s *RequestHandler) enronWidth(functionCode byte, address uint16, quantity uint16) (int, *common.ProtocolDataUnit) {
+	width, err := s.store.EnronLayout().Width(address, quantity)
+	if err != nil {
+		return 0, &common.ProtocolDataUnit{
+			FunctionCode: functionCode | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataAddress},
+		}
+	}
+	return width, nil
+}
+
+// handleReadEnronRegisters 处理 32 位区间的读取保持寄存器请求 (功能码 0x03)
+func (s *RequestHandler) handleReadEnronRegisters(address uint16, quantity uint16) *common.ProtocolDataUnit {
+	if quantity > common.MaxEnronReadRegisters {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReadHoldingRegisters | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataValue},
+		}
+	}
+	values := s.store.ReadEnronRegisters(address, quantity)
+
+	responseData := make([]byte, 1, 1+len(values)*4)
+	responseData[0] = byte(len(values) * 4)
+	responseData = s.store.EnronLayout().AppendValues(responseData, values)
+
+	return &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeReadHoldingRegisters,
+		Data:         responseData,
+	}
+}
+
+// handleWriteSingleEnronRegister 处理 32 位区间的写入单个寄存器请求 (功能码 0x06)，值为 4 字节
+func (s *RequestHandler) handleWriteSingleEnronRegister(request *common.ProtocolDataUnit) *common.ProtocolDataUnit {
+	if len(request.Data) != 6 {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeWriteSingleRegister | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataValue},
+		}
+	}
+
+	address := binary.BigEndian.Uint16(request.Data[0:2])
+	values, err := s.store.EnronLayout().DecodeValues(request.Data[2:6])
+	if err != nil {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeWriteSingleRegister | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataValue},
+		}
+	}
+	s.store.WriteEnronRegisters(address, values)
+
+	return &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeWriteSingleRegister,
+		Data:         request.Data,
+	}
+}
+
+// handleWriteMultipleEnronRegisters 处理 32 位区间的写入多个寄存器请求 (功能码 0x10)，每个值为 4 字节
+func (s *RequestHandler) handleWriteMultipleEnronRegisters(request *common.ProtocolDataUnit, address uint16, quantity uint16) *common.ProtocolDataUnit {
+	byteCount := int(request.Data[4])
+	if quantity < 1 || quantity > common.MaxEnronWriteRegisters || byteCount != int(quantity)*4 {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeWriteMultipleRegisters | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataValue},
+		}
+	}
+	if len(request.Data) < 5+byteCount {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeWriteMultipleRegisters | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataAddress},
+		}
+	}
+
+	values, err := s.store.EnronLayout().DecodeValues(request.Data[5 : 5+byteCount])
+	if err != nil {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeWriteMultipleRegisters | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataValue},
+		}
+	}
+	s.store.WriteEnronRegisters(address, values)
+
+	responseData := make([]byte, 4)
+	binary.BigEndian.PutUint16(responseData[0:2], address)
+	binary.BigEndian.PutUint16(responseData[2:4], quantity)
+
+	return &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeWriteMultipleRegisters,
+		Data:         responseData,
+	}
+}
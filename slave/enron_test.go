@@ -0,0 +1,115 @@
+package slave_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+func TestEnronRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  common.ProtocolDataUnit
+		response common.ProtocolDataUnit
+	}{
+		{
+			name:     "read 32-bit registers",
+			request:  common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadHoldingRegisters, Data: []byte{0x13, 0x89, 0x00, 0x02}},
+			response: common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadHoldingRegisters, Data: []byte{8, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88}},
+		},
+		{
+			name:     "read 16-bit registers before range",
+			request:  common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadHoldingRegisters, Data: []byte{0x13, 0x87, 0x00, 0x02}},
+			response: common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadHoldingRegisters, Data: []byte{4, 0x00, 0x00, 0x00, 0x00}},
+		},
+		{
+			name:     "read crosses range start",
+			request:  common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadHoldingRegisters, Data: []byte{0x13, 0x87, 0x00, 0x03}},
+			response: common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadHoldingRegisters | 0x80, Data: []byte{common.ExceptionCodeIllegalDataAddress}},
+		},
+		{
+			name:     "read crosses range end",
+			request:  common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadHoldingRegisters, Data: []byte{0x17, 0x6F, 0x00, 0x02}},
+			response: common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadHoldingRegisters | 0x80, Data: []byte{common.ExceptionCodeIllegalDataAddress}},
+		},
+		{
+			name:     "read too many 32-bit registers",
+			request:  common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadHoldingRegisters, Data: []byte{0x13, 0x89, 0x00, common.MaxEnronReadRegisters + 1}},
+			response: common.ProtocolDataUnit{FunctionCode: common.FuncCodeReadHoldingRegisters | 0x80, Data: []byte{common.ExceptionCodeIllegalDataValue}},
+		},
+		{
+			name:     "write single 32-bit register",
+			request:  common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteSingleRegister, Data: []byte{0x13, 0x8B, 0xDE, 0xAD, 0xBE, 0xEF}},
+			response: common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteSingleRegister, Data: []byte{0x13, 0x8B, 0xDE, 0xAD, 0xBE, 0xEF}},
+		},
+		{
+			name:     "write single 32-bit register with 16-bit value",
+			request:  common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteSingleRegister, Data: []byte{0x13, 0x8B, 0xBE, 0xEF}},
+			response: common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteSingleRegister | 0x80, Data: []byte{common.ExceptionCodeIllegalDataValue}},
+		},
+		{
+			name:     "write multiple 32-bit registers",
+			request:  common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteMultipleRegisters, Data: []byte{0x1B, 0x59, 0x00, 0x02, 8, 0x3F, 0x80, 0x00, 0x00, 0x40, 0x00, 0x00, 0x00}},
+			response: common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteMultipleRegisters, Data: []byte{0x1B, 0x59, 0x00, 0x02}},
+		},
+		{
+			name:     "write multiple with 16-bit byte count",
+			request:  common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteMultipleRegisters, Data: []byte{0x1B, 0x59, 0x00, 0x02, 4, 0x3F, 0x80, 0x40, 0x00}},
+			response: common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteMultipleRegisters | 0x80, Data: []byte{common.ExceptionCodeIllegalDataValue}},
+		},
+		{
+			name:     "write multiple crosses range end",
+			request:  common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteMultipleRegisters, Data: []byte{0x17, 0x6E, 0x00, 0x03, 12, 0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 3}},
+			response: common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteMultipleRegisters | 0x80, Data: []byte{common.ExceptionCodeIllegalDataAddress}},
+		},
+		{
+			name:     "write multiple crosses range start",
+			request:  common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteMultipleRegisters, Data: []byte{0x1B, 0x58, 0x00, 0x02, 4, 0x00, 0x01, 0x00, 0x02}},
+			response: common.ProtocolDataUnit{FunctionCode: common.FuncCodeWriteMultipleRegisters | 0x80, Data: []byte{common.ExceptionCodeIllegalDataAddress}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := slave.NewMemoryDataStore()
+			store.SetEnronLayout(common.DefaultEnronLayout())
+			store.WriteEnronRegisters(5001, []uint32{0x11223344, 0x55667788})
+			handler := slave.NewRequestHandler(&slave.DeviceInfo{}, store)
+
+			response, err := handler.HandleRequest(&tt.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.FunctionCode != tt.response.FunctionCode || !bytes.Equal(response.Data, tt.response.Data) {
+				t.Fatalf("response = %#x % x, want %#x % x", response.FunctionCode, response.Data, tt.response.FunctionCode, tt.response.Data)
+			}
+		})
+	}
+}
+
+func TestEnronWritesStoreValues(t *testing.T) {
+	store := slave.NewMemoryDataStore()
+	store.SetEnronLayout(common.DefaultEnronLayout())
+	handler := slave.NewRequestHandler(&slave.DeviceInfo{}, store)
+
+	requests := []*common.ProtocolDataUnit{
+		{FunctionCode: common.FuncCodeWriteSingleRegister, Data: []byte{0x13, 0x8B, 0xDE, 0xAD, 0xBE, 0xEF}},
+		{FunctionCode: common.FuncCodeWriteMultipleRegisters, Data: []byte{0x1B, 0x59, 0x00, 0x02, 8, 0x3F, 0x80, 0x00, 0x00, 0x40, 0x00, 0x00, 0x00}},
+	}
+	for _, request := range requests {
+		if _, err := handler.HandleRequest(request); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := store.ReadEnronRegisters(5003, 1); got[0] != 0xDEADBEEF {
+		t.Fatalf("register 5003 = %#x, want %#x", got[0], uint32(0xDEADBEEF))
+	}
+	if got := store.ReadEnronRegisters(7001, 2); got[0] != 0x3F800000 || got[1] != 0x40000000 {
+		t.Fatalf("registers 7001-7002 = %#x", got)
+	}
+	// 32 位区间的写入不影响同地址的 16 位保持寄存器
+	if got := store.Read(slave.PointTypeHoldingRegister, 5003); got != 0 {
+		t.Fatalf("holding register 5003 = %#x, want 0", got)
+	}
+}
@@ -0,0 +1,86 @@
+package slave
+
+import (
+	"errors"
+	"sync"
+)
+
+// FIFOQueueMaxCount FIFO 队列最多可容纳的寄存器数量（Read FIFO Queue 单次最多返回 31 个）
+const FIFOQueueMaxCount = 31
+
+var ErrFIFOQueueFull = errors.New("slave: fifo queue is full")
+
+// FIFOQueue 先进先出寄存器队列，供 Read FIFO Queue (功能码 0x18) 读取
+type FIFOQueue struct {
+	mu       sync.Mutex
+	values   []uint16
+	capacity int // 为 0 时使用 FIFOQueueMaxCount
+}
+
+// NewFIFOQueue 创建一个容量为 FIFOQueueMaxCount 的空 FIFO 队列
+func NewFIFOQueue() *FIFOQueue {
+	return &FIFOQueue{
+		values: make([]uint16, 0, FIFOQueueMaxCount),
+	}
+}
+
+// SetCapacity 设置队列容量，设备的队列可以大于 FIFOQueueMaxCount，
+// 此时数据超过 FIFOQueueMaxCount 个的队列读取时返回非法数据值异常
+func (q *FIFOQueue) SetCapacity(capacity int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.capacity = capacity
+}
+
+// Push 向队尾追加数据，超出容量时不写入任何数据并返回 ErrFIFOQueueFull
+func (q *FIFOQueue) Push(values ...uint16) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	capacity := q.capacity
+	if capacity == 0 {
+		capacity = FIFOQueueMaxCount
+	}
+	if len(q.values)+len(values) > capacity {
+		return ErrFIFOQueueFull
+	}
+	q.values = append(q.values, values...)
+	return nil
+}
+
+// Pop 取出队首数据
+func (q *FIFOQueue) Pop() (value uint16, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.values) == 0 {
+		return 0, false
+	}
+	value = q.values[0]
+	q.values = append(q.values[:0], q.values[1:]...)
+	return value, true
+}
+
+// Values 返回队列中全部数据的副本，不移除数据
+func (q *FIFOQueue) Values() []uint16 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	values := make([]uint16, len(q.values))
+	copy(values, q.values)
+	return values
+}
+
+// Len 返回队列中的数据个数
+func (q *FIFOQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.values)
+}
+
+// Clear 清空队列
+func (q *FIFOQueue) Clear() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.values = q.values[:0]
+}
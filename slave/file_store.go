@@ -0,0 +1,77 @@
+package slave
+
+import (
+	"errors"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+var (
+	ErrFileNotFound         = errors.New("slave: file not found")
+	ErrFileRecordOutOfRange = errors.New("slave: file record out of range")
+)
+
+// CreateFile 创建（或重建）文件，size 为文件包含的记录数量，每条记录为一个寄存器
+func (m *MemoryDataStore) CreateFile(fileNumber uint16, size uint16) {
+	if int(size) > common.FileRecordMaxRecordNumber+1 {
+		size = common.FileRecordMaxRecordNumber + 1
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[fileNumber] = make([]uint16, size)
+}
+
+// RemoveFile 删除文件
+func (m *MemoryDataStore) RemoveFile(fileNumber uint16) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, fileNumber)
+}
+
+// CheckFileRecords 检查文件记录范围是否有效
+func (m *MemoryDataStore) CheckFileRecords(fileNumber uint16, recordNumber uint16, length uint16) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, err := m.fileRecords(fileNumber, recordNumber, length)
+	return err
+}
+
+// ReadFileRecords 读取文件中从 recordNumber 开始的 length 条记录
+func (m *MemoryDataStore) ReadFileRecords(fileNumber uint16, recordNumber uint16, length uint16) ([]uint16, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	records, err := m.fileRecords(fileNumber, recordNumber, length)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]uint16, length)
+	copy(values, records)
+	return values, nil
+}
+
+// WriteFileRecords 从 recordNumber 开始写入文件记录
+func (m *MemoryDataStore) WriteFileRecords(fileNumber uint16, recordNumber uint16, values []uint16) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	records, err := m.fileRecords(fileNumber, recordNumber, uint16(len(values)))
+	if err != nil {
+		return err
+	}
+	copy(records, values)
+	return nil
+}
+
+// fileRecords 返回文件记录的切片，调用方需持有锁
+func (m *MemoryDataStore) fileRecords(fileNumber uint16, recordNumber uint16, length uint16) ([]uint16, error) {
+	file, ok := m.files[fileNumber]
+	if !ok {
+		return nil, ErrFileNotFound
+	}
+	end := int(recordNumber) + int(length)
+	if length == 0 || recordNumber > common.FileRecordMaxRecordNumber || end > len(file) {
+		return nil, ErrFileRecordOutOfRange
+	}
+	return file[recordNumber:end], nil
+}
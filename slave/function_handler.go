@@ -0,0 +1,61 @@
+package slave
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// FunctionHandler 自定义功能码的处理函数。
+// 返回 *common.Error 时以其中的异常码响应，返回其它错误时以从站设备故障响应，response 与 err 均为 nil 时不返回响应
+type FunctionHandler func(ctx context.Context, request *common.ProtocolDataUnit) (*common.ProtocolDataUnit, error)
+
+// RegisterFunction 注册功能码的处理函数，已注册的处理函数优先于内置实现，handler 为 nil 时取消注册
+func (s *RequestHandler) RegisterFunction(functionCode byte, handler FunctionHandler) error {
+	if functionCode == 0 || functionCode&0x80 != 0 {
+		return fmt.Errorf("modbus: function code '%v' is out of range [1, 127]", functionCode)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if handler == nil {
+		delete(s.functions, functionCode)
+		return nil
+	}
+	if s.functions == nil {
+		s.functions = make(map[byte]FunctionHandler)
+	}
+	s.functions[functionCode] = handler
+	return nil
+}
+
+// function 返回功能码已注册的处理函数
+func (s *RequestHandler) function(functionCode byte) (FunctionHandler, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	handler, ok := s.functions[functionCode]
+	return handler, ok
+}
+
+// handleFunction 调用自定义处理函数，并将返回的错误转换为异常响应
+func (s *RequestHandler) handleFunction(ctx context.Context, handler FunctionHandler, request *common.ProtocolDataUnit) *common.ProtocolDataUnit {
+	response, err := handler(ctx, request)
+	if err == nil {
+		return response
+	}
+	return errorResponse(request.FunctionCode, err)
+}
+
+// errorResponse 将处理函数返回的错误转换为异常响应，*common.Error 使用其中的异常码，其它错误为从站设备故障
+func errorResponse(functionCode byte, err error) *common.ProtocolDataUnit {
+	exceptionCode := byte(common.ExceptionCodeServerDeviceFailure)
+	var mbError *common.Error
+	if errors.As(err, &mbError) && mbError.ExceptionCode != 0 {
+		exceptionCode = mbError.ExceptionCode
+	}
+	return &common.ProtocolDataUnit{
+		FunctionCode: functionCode | 0x80,
+		Data:         []byte{exceptionCode},
+	}
+}
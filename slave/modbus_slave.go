@@ -1,25 +1,85 @@
 package slave
 
 import (
+	"errors"
+
 	"github.com/veryinf/modbus-kit/common"
 )
 
 type DeviceInfo struct {
 	Title          string
 	Identification *common.DeviceIdentification
+	// Report Server ID (功能码 0x11) 的响应内容，ServerID 为空时不支持该功能码
+	ServerID       []byte
+	RunIndicator   bool
+	AdditionalData []byte
 }
 
+// ErrNoRequestHandler 从站的通信层未内嵌 RequestHandler，设置处理器的方法无法作用于实际处理请求的处理器
+var ErrNoRequestHandler = errors.New("slave: transport does not expose a request handler")
+
 type ModbusSlave struct {
 	common.ModbusDevice
 	DeviceInfo *DeviceInfo
 	Store      *MemoryDataStore
+	// Handler 通信层内嵌的请求处理器，通信层未内嵌 RequestHandler 时为 nil
+	Handler *RequestHandler
 }
 
-// NewModbusSlave 创建一个新的 ModbusSlave 对象
+// NewModbusSlave 创建一个新的 ModbusSlave 对象，通信层内嵌 RequestHandler 时 Handler 使用该处理器，
+// 否则 Handler 为 nil，SetExceptionStatus 等设置处理器的方法返回 ErrNoRequestHandler
 func NewModbusSlave(slaveInfo common.ModbusDevice, deviceInfo *DeviceInfo, store *MemoryDataStore) *ModbusSlave {
 	slave := ModbusSlave{
 		DeviceInfo: deviceInfo, Store: store,
 	}
 	slave.ModbusDevice = slaveInfo
+	if t, ok := slaveInfo.Transport.(interface{ requestHandler() *RequestHandler }); ok {
+		slave.Handler = t.requestHandler()
+	}
 	return &slave
 }
+
+// SetExceptionStatus 设置 Read Exception Status (功能码 0x07) 返回的异常状态
+func (s *ModbusSlave) SetExceptionStatus(status byte) error {
+	if s.Handler == nil {
+		return ErrNoRequestHandler
+	}
+	s.Handler.SetExceptionStatus(status)
+	return nil
+}
+
+// SetBusy 设置 Get Comm Event Counter/Log (功能码 0x0B/0x0C) 返回的状态字是否为忙
+func (s *ModbusSlave) SetBusy(busy bool) error {
+	if s.Handler == nil {
+		return ErrNoRequestHandler
+	}
+	s.Handler.SetBusy(busy)
+	return nil
+}
+
+// RegisterFunction 注册自定义功能码的处理函数，handler 为 nil 时取消注册
+func (s *ModbusSlave) RegisterFunction(functionCode byte, handler FunctionHandler) error {
+	if s.Handler == nil {
+		return ErrNoRequestHandler
+	}
+	return s.Handler.RegisterFunction(functionCode, handler)
+}
+
+// SetCANopenHandler 设置 CANopen General Reference (MEI 类型 0x0D) 的处理函数
+func (s *ModbusSlave) SetCANopenHandler(handler CANopenHandler) error {
+	if s.Handler == nil {
+		return ErrNoRequestHandler
+	}
+	s.Handler.SetCANopenHandler(handler)
+	return nil
+}
+
+// SetAuthorizer 设置请求授权函数，未授权的请求以非法功能异常响应，authorizer 为 nil 时不做授权检查。
+// 通信层未内嵌 RequestHandler 时返回 ErrNoRequestHandler，此时授权函数不会生效
+func (s *ModbusSlave) SetAuthorizer(authorizer Authorizer) error {
+	if s.Handler == nil {
+		return ErrNoRequestHandler
+	}
+	s.Handler.SetAuthorizer(authorizer)
+	return nil
+}
@@ -0,0 +1,56 @@
+package slave_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/veryinf/modbus-kit/common"
+	"github.com/veryinf/modbus-kit/slave"
+)
+
+// echoTransport 不内嵌 RequestHandler 的通信层
+type echoTransport struct{}
+
+func (echoTransport) Send(requestData []byte) ([]byte, error) {
+	return requestData, nil
+}
+
+func TestModbusSlaveSetters(t *testing.T) {
+	authorizer := func(ctx context.Context, request *common.ProtocolDataUnit) bool { return false }
+	setters := map[string]func(s *slave.ModbusSlave) error{
+		"SetExceptionStatus": func(s *slave.ModbusSlave) error { return s.SetExceptionStatus(0x01) },
+		"SetBusy":            func(s *slave.ModbusSlave) error { return s.SetBusy(true) },
+		"RegisterFunction":   func(s *slave.ModbusSlave) error { return s.RegisterFunction(65, nil) },
+		"SetCANopenHandler":  func(s *slave.ModbusSlave) error { return s.SetCANopenHandler(nil) },
+		"SetAuthorizer":      func(s *slave.ModbusSlave) error { return s.SetAuthorizer(authorizer) },
+	}
+	custom := slave.NewModbusSlave(common.ModbusDevice{SlaveId: 1, FrameType: common.FrameTypeMBAP, Transport: echoTransport{}},
+		&slave.DeviceInfo{}, slave.NewMemoryDataStore())
+	tcp := slave.NewModbusTCPSlave(1, &slave.DeviceInfo{}, slave.NewMemoryDataStore())
+	for name, set := range setters {
+		if err := set(custom); !errors.Is(err, slave.ErrNoRequestHandler) {
+			t.Errorf("%v without request handler: got %v, want %v", name, err, slave.ErrNoRequestHandler)
+		}
+		if err := set(tcp); err != nil {
+			t.Errorf("%v on tcp slave: %v", name, err)
+		}
+	}
+
+	// 授权函数作用于通信层实际使用的处理器
+	request := common.NewMBAPFrame(1, 1, &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeReadHoldingRegisters,
+		Data:         []byte{0x00, 0x00, 0x00, 0x01},
+	}).ToBytes()
+	responseData, err := tcp.Transport.Send(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame, err := common.NewMBAPFrameFromBytes(responseData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frame.PDU.FunctionCode != common.FuncCodeReadHoldingRegisters|0x80 || frame.PDU.Data[0] != common.ExceptionCodeIllegalFunction {
+		t.Fatalf("unauthorized response = %v %v, want illegal function exception", frame.PDU.FunctionCode, frame.PDU.Data)
+	}
+}
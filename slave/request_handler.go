@@ -1,19 +1,88 @@
 package slave
 
 import (
+	"context"
 	"encoding/binary"
+	"fmt"
+	"slices"
+	"sync"
+
 	"github.com/veryinf/modbus-kit/common"
 )
 
 type RequestHandler struct {
-	DeviceInfo *DeviceInfo
-	store      *MemoryDataStore
+	DeviceInfo  *DeviceInfo
+	store       *MemoryDataStore
+	diagnostics diagnostics
+
+	mu         sync.RWMutex
+	functions  map[byte]FunctionHandler
+	canopen    CANopenHandler
+	authorizer Authorizer
+}
+
+// NewRequestHandler 创建一个新的 RequestHandler 对象，零值的 RequestHandler 设置 DeviceInfo 和存储后同样可用
+func NewRequestHandler(deviceInfo *DeviceInfo, store *MemoryDataStore) *RequestHandler {
+	return &RequestHandler{
+		DeviceInfo: deviceInfo,
+		store:      store,
+	}
+}
+
+// requestHandler 返回处理器本身，用于 NewModbusSlave 取得通信层内嵌的处理器
+func (s *RequestHandler) requestHandler() *RequestHandler {
+	return s
 }
 
-// HandleRequest 处理 Modbus 请求
+// HandleRequest 处理 Modbus 请求，response 为 nil 表示不返回响应
 func (s *RequestHandler) HandleRequest(request *common.ProtocolDataUnit) (response *common.ProtocolDataUnit, err error) {
-	response = &common.ProtocolDataUnit{
-		FunctionCode: request.FunctionCode,
+	return s.HandleRequestContext(context.Background(), request)
+}
+
+// HandleRequestContext 处理 Modbus 请求，ctx 传递给授权函数和自定义处理函数，response 为 nil 表示不返回响应
+func (s *RequestHandler) HandleRequestContext(ctx context.Context, request *common.ProtocolDataUnit) (response *common.ProtocolDataUnit, err error) {
+	s.logReceive(false)
+	defer func() {
+		s.countResponse(request, response)
+	}()
+
+	// 仅监听模式下只处理重启通信选项，其余请求均不响应
+	if s.ListenOnly() && !isRestartCommunicationsOption(request) {
+		return nil, nil
+	}
+	if !s.authorize(ctx, request) {
+		return &common.ProtocolDataUnit{
+			FunctionCode: request.FunctionCode | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalFunction},
+		}, nil
+	}
+	return s.dispatch(ctx, request), nil
+}
+
+// HandleBroadcast 处理广播请求，只执行写请求且不返回响应，其它请求返回错误
+func (s *RequestHandler) HandleBroadcast(ctx context.Context, request *common.ProtocolDataUnit) error {
+	s.logReceive(true)
+	defer s.countResponse(request, nil)
+
+	if !slices.Contains(common.AvailableWriteFunctionCodes, request.FunctionCode) {
+		return fmt.Errorf("modbus: function code '%v' is not allowed in broadcast", request.FunctionCode)
+	}
+	// 仅监听模式下不执行任何请求
+	if s.ListenOnly() {
+		return nil
+	}
+	if !s.authorize(ctx, request) {
+		return fmt.Errorf("modbus: function code '%v' is not authorized", request.FunctionCode)
+	}
+	s.dispatch(ctx, request)
+	return nil
+}
+
+// dispatch 按功能码分发请求，返回 nil 表示不返回响应
+func (s *RequestHandler) dispatch(ctx context.Context, request *common.ProtocolDataUnit) (response *common.ProtocolDataUnit) {
+	// 优先使用注册的处理函数
+	if handler, ok := s.function(request.FunctionCode); ok {
+		return s.handleFunction(ctx, handler, request)
 	}
 
 	switch request.FunctionCode {
@@ -29,21 +98,44 @@ func (s *RequestHandler) HandleRequest(request *common.ProtocolDataUnit) (respon
 		response = s.handleWriteSingleCoil(request)
 	case common.FuncCodeWriteSingleRegister:
 		response = s.handleWriteSingleRegister(request)
+	case common.FuncCodeReadExceptionStatus:
+		response = s.handleReadExceptionStatus(request)
+	case common.FuncCodeDiagnostics:
+		response = s.handleDiagnostics(request)
+	case common.FuncCodeGetCommEventCounter:
+		response = s.handleGetCommEventCounter(request)
+	case common.FuncCodeGetCommEventLog:
+		response = s.handleGetCommEventLog(request)
 	case common.FuncCodeWriteMultipleCoils:
 		response = s.handleWriteMultipleCoils(request)
 	case common.FuncCodeWriteMultipleRegisters:
 		response = s.handleWriteMultipleRegisters(request)
+	case common.FuncCodeReportServerID:
+		response = s.handleReportServerID(request)
+	case common.FuncCodeReadFileRecord:
+		response = s.handleReadFileRecord(request)
+	case common.FuncCodeWriteFileRecord:
+		response = s.handleWriteFileRecord(request)
+	case common.FuncCodeMaskWriteRegister:
+		response = s.handleMaskWriteRegister(request)
+	case common.FuncCodeReadWriteMultipleRegisters:
+		response = s.handleReadWriteMultipleRegisters(request)
+	case common.FuncCodeReadFIFOQueue:
+		response = s.handleReadFIFOQueue(request)
 	case common.FuncCodeReadDeviceIdentification:
-		response = s.handleReadDeviceIdentification(request)
+		if len(request.Data) > 0 && request.Data[0] == common.MEITypeCANopenGeneralReference {
+			response = s.handleCANopenGeneralReference(ctx, request)
+		} else {
+			response = s.handleReadDeviceIdentification(request)
+		}
 	default:
 		response = &common.ProtocolDataUnit{
 			FunctionCode: request.FunctionCode | 0x80,
 			Data:         []byte{common.ExceptionCodeIllegalFunction},
 		}
-		return response, nil
 	}
 
-	return response, nil
+	return response
 }
 
 // handleReadCoils 处理读取线圈请求 (功能码 0x01)
@@ -64,12 +156,7 @@ func (s *RequestHandler) handleReadCoils(request *common.ProtocolDataUnit) *comm
 			Data:         []byte{common.ExceptionCodeIllegalDataValue},
 		}
 	}
-	bitVector := common.NewBitVector(uint(quantity))
-	for i := uint16(0); i < quantity; i++ {
-		value := s.store.Read(PointTypeCoil, address+i)
-		bitVector.Set(uint(i), value != 0)
-	}
-	bytes := bitVector.ToBytes()
+	bytes := s.store.ReadBits(PointTypeCoil, address, quantity).ToBytes()
 
 	// 构建响应: [字节数] [数据...]
 	responseData := make([]byte, 1+len(bytes))
@@ -101,12 +188,7 @@ func (s *RequestHandler) handleReadDiscreteInputs(request *common.ProtocolDataUn
 		}
 	}
 
-	bitVector := common.NewBitVector(uint(quantity))
-	for i := uint16(0); i < quantity; i++ {
-		value := s.store.Read(PointTypeDiscreteInput, address+i)
-		bitVector.Set(uint(i), value != 0)
-	}
-	bytes := bitVector.ToBytes()
+	bytes := s.store.ReadBits(PointTypeDiscreteInput, address, quantity).ToBytes()
 
 	responseData := make([]byte, 1+len(bytes))
 	responseData[0] = byte(len(bytes))
@@ -137,16 +219,17 @@ func (s *RequestHandler) handleReadHoldingRegisters(request *common.ProtocolData
 		}
 	}
 
-	registers := make([]*common.Register, quantity)
-	for i := uint16(0); i < quantity; i++ {
-		registers[i] = common.NewRegisterFromUInt16(s.store.Read(PointTypeHoldingRegister, address+i))
+	width, exception := s.enronWidth(common.FuncCodeReadHoldingRegisters, address, quantity)
+	if exception != nil {
+		return exception
+	}
+	if width == 4 {
+		return s.handleReadEnronRegisters(address, quantity)
 	}
 
-	bytes := common.RegistersToBytes(registers)
-
-	responseData := make([]byte, 1+len(*bytes))
-	responseData[0] = byte(len(*bytes))
-	copy(responseData[1:], *bytes)
+	responseData := make([]byte, 1, 1+quantity*2)
+	responseData[0] = byte(quantity * 2)
+	responseData = s.store.AppendRegisterBytes(responseData, PointTypeHoldingRegister, address, quantity)
 
 	return &common.ProtocolDataUnit{
 		FunctionCode: common.FuncCodeReadHoldingRegisters,
@@ -173,16 +256,9 @@ func (s *RequestHandler) handleReadInputRegisters(request *common.ProtocolDataUn
 		}
 	}
 
-	registers := make([]*common.Register, quantity)
-	for i := uint16(0); i < quantity; i++ {
-		registers[i] = common.NewRegisterFromUInt16(s.store.Read(PointTypeInputRegister, address+i))
-	}
-
-	bytes := common.RegistersToBytes(registers)
-
-	responseData := make([]byte, 1+len(*bytes))
-	responseData[0] = byte(len(*bytes))
-	copy(responseData[1:], *bytes)
+	responseData := make([]byte, 1, 1+quantity*2)
+	responseData[0] = byte(quantity * 2)
+	responseData = s.store.AppendRegisterBytes(responseData, PointTypeInputRegister, address, quantity)
 
 	return &common.ProtocolDataUnit{
 		FunctionCode: common.FuncCodeReadInputRegisters,
@@ -227,6 +303,9 @@ func (s *RequestHandler) handleWriteSingleRegister(request *common.ProtocolDataU
 	}
 
 	address := binary.BigEndian.Uint16(request.Data[0:2])
+	if s.store.EnronLayout().Is32Bit(address) {
+		return s.handleWriteSingleEnronRegister(request)
+	}
 	value := binary.BigEndian.Uint16(request.Data[2:4])
 
 	s.store.Write(PointTypeHoldingRegister, address, value)
@@ -272,15 +351,8 @@ func (s *RequestHandler) handleWriteMultipleCoils(request *common.ProtocolDataUn
 		}
 	}
 
-	bitVector := common.NewBitVector(uint(quantity))
-	bitVector.Load(request.Data[5 : 5+byteCount])
-	for i := uint16(0); i < quantity; i++ {
-		var val uint16 = 0
-		if bitVector.Get(uint(i)) {
-			val = 1
-		}
-		s.store.Write(PointTypeCoil, address+i, val)
-	}
+	bitVector := common.NewBitVectorFromBytes(request.Data[5:5+byteCount], uint(quantity))
+	s.store.WriteBits(PointTypeCoil, address, bitVector)
 
 	responseData := make([]byte, 4)
 	binary.BigEndian.PutUint16(responseData[0:2], address)
@@ -305,6 +377,14 @@ func (s *RequestHandler) handleWriteMultipleRegisters(request *common.ProtocolDa
 	quantity := binary.BigEndian.Uint16(request.Data[2:4])
 	byteCount := int(request.Data[4])
 
+	width, exception := s.enronWidth(common.FuncCodeWriteMultipleRegisters, address, quantity)
+	if exception != nil {
+		return exception
+	}
+	if width == 4 {
+		return s.handleWriteMultipleEnronRegisters(request, address, quantity)
+	}
+
 	if quantity < 1 || quantity > 123 {
 		return &common.ProtocolDataUnit{
 			FunctionCode: common.FuncCodeWriteMultipleRegisters | 0x80,
@@ -327,11 +407,7 @@ func (s *RequestHandler) handleWriteMultipleRegisters(request *common.ProtocolDa
 		}
 	}
 
-	registers := common.NewRegisters(request.Data[5 : 5+byteCount])
-
-	for i, reg := range registers {
-		s.store.Write(PointTypeHoldingRegister, address+uint16(i), reg.Value())
-	}
+	s.store.WriteRegisterBytes(PointTypeHoldingRegister, address, request.Data[5:5+byteCount])
 
 	responseData := make([]byte, 4)
 	binary.BigEndian.PutUint16(responseData[0:2], address)
@@ -343,12 +419,296 @@ func (s *RequestHandler) handleWriteMultipleRegisters(request *common.ProtocolDa
 	}
 }
 
+// handleReportServerID 处理报告服务器 ID 请求 (功能码 0x11)
+func (s *RequestHandler) handleReportServerID(request *common.ProtocolDataUnit) *common.ProtocolDataUnit {
+	if s.DeviceInfo == nil || len(s.DeviceInfo.ServerID) == 0 {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReportServerID | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalFunction},
+		}
+	}
+	serverID := &common.ServerID{
+		ID:             s.DeviceInfo.ServerID,
+		RunIndicator:   s.DeviceInfo.RunIndicator,
+		AdditionalData: s.DeviceInfo.AdditionalData,
+	}
+	data := serverID.Bytes()
+	// 字节数之后的数据最多 251 字节
+	if len(data) > 251 {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReportServerID | 0x80,
+			Data:         []byte{common.ExceptionCodeServerDeviceFailure},
+		}
+	}
+
+	// 构建响应: [字节数] [服务器 ID] [运行指示] [附加数据...]
+	responseData := make([]byte, 1+len(data))
+	responseData[0] = byte(len(data))
+	copy(responseData[1:], data)
+
+	return &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeReportServerID,
+		Data:         responseData,
+	}
+}
+
+// handleReadFileRecord 处理读文件记录请求 (功能码 0x14)
+func (s *RequestHandler) handleReadFileRecord(request *common.ProtocolDataUnit) *common.ProtocolDataUnit {
+	if len(request.Data) < 1 {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReadFileRecord | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataValue},
+		}
+	}
+
+	byteCount := int(request.Data[0])
+	if byteCount < 0x07 || byteCount > 0xF5 || byteCount%7 != 0 || len(request.Data) < 1+byteCount {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReadFileRecord | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataValue},
+		}
+	}
+
+	// 构建响应: [响应数据长度] [文件响应长度] [参考类型] [记录数据...] ...
+	responseData := []byte{0x00}
+	for offset := 1; offset < 1+byteCount; offset += 7 {
+		referenceType := request.Data[offset]
+		fileNumber := binary.BigEndian.Uint16(request.Data[offset+1:])
+		recordNumber := binary.BigEndian.Uint16(request.Data[offset+3:])
+		recordLength := binary.BigEndian.Uint16(request.Data[offset+5:])
+		if referenceType != common.FileRecordReferenceType {
+			return &common.ProtocolDataUnit{
+				FunctionCode: common.FuncCodeReadFileRecord | 0x80,
+				Data:         []byte{common.ExceptionCodeIllegalDataAddress},
+			}
+		}
+		values, err := s.store.ReadFileRecords(fileNumber, recordNumber, recordLength)
+		if err != nil {
+			return &common.ProtocolDataUnit{
+				FunctionCode: common.FuncCodeReadFileRecord | 0x80,
+				Data:         []byte{common.ExceptionCodeIllegalDataAddress},
+			}
+		}
+		if len(responseData)+2+len(values)*2 > 0xF6 {
+			return &common.ProtocolDataUnit{
+				FunctionCode: common.FuncCodeReadFileRecord | 0x80,
+				Data:         []byte{common.ExceptionCodeIllegalDataValue},
+			}
+		}
+		responseData = append(responseData, byte(1+len(values)*2), common.FileRecordReferenceType)
+		for _, value := range values {
+			responseData = append(responseData, byte(value>>8), byte(value))
+		}
+	}
+	responseData[0] = byte(len(responseData) - 1)
+
+	return &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeReadFileRecord,
+		Data:         responseData,
+	}
+}
+
+// handleWriteFileRecord 处理写文件记录请求 (功能码 0x15)
+func (s *RequestHandler) handleWriteFileRecord(request *common.ProtocolDataUnit) *common.ProtocolDataUnit {
+	if len(request.Data) < 1 {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeWriteFileRecord | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataValue},
+		}
+	}
+
+	byteCount := int(request.Data[0])
+	if byteCount < 0x09 || byteCount > 0xFB || len(request.Data) < 1+byteCount {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeWriteFileRecord | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataValue},
+		}
+	}
+
+	// 先校验全部子请求，避免部分写入
+	type subRequest struct {
+		fileNumber   uint16
+		recordNumber uint16
+		values       []uint16
+	}
+	subRequests := make([]subRequest, 0)
+	for offset := 1; offset < 1+byteCount; {
+		if offset+7 > 1+byteCount {
+			return &common.ProtocolDataUnit{
+				FunctionCode: common.FuncCodeWriteFileRecord | 0x80,
+				Data:         []byte{common.ExceptionCodeIllegalDataValue},
+			}
+		}
+		referenceType := request.Data[offset]
+		fileNumber := binary.BigEndian.Uint16(request.Data[offset+1:])
+		recordNumber := binary.BigEndian.Uint16(request.Data[offset+3:])
+		recordLength := binary.BigEndian.Uint16(request.Data[offset+5:])
+		end := offset + 7 + int(recordLength)*2
+		if end > 1+byteCount {
+			return &common.ProtocolDataUnit{
+				FunctionCode: common.FuncCodeWriteFileRecord | 0x80,
+				Data:         []byte{common.ExceptionCodeIllegalDataValue},
+			}
+		}
+		if referenceType != common.FileRecordReferenceType {
+			return &common.ProtocolDataUnit{
+				FunctionCode: common.FuncCodeWriteFileRecord | 0x80,
+				Data:         []byte{common.ExceptionCodeIllegalDataAddress},
+			}
+		}
+		if err := s.store.CheckFileRecords(fileNumber, recordNumber, recordLength); err != nil {
+			return &common.ProtocolDataUnit{
+				FunctionCode: common.FuncCodeWriteFileRecord | 0x80,
+				Data:         []byte{common.ExceptionCodeIllegalDataAddress},
+			}
+		}
+		values := make([]uint16, recordLength)
+		for i := range values {
+			values[i] = binary.BigEndian.Uint16(request.Data[offset+7+i*2:])
+		}
+		subRequests = append(subRequests, subRequest{fileNumber, recordNumber, values})
+		offset = end
+	}
+
+	for _, sub := range subRequests {
+		if err := s.store.WriteFileRecords(sub.fileNumber, sub.recordNumber, sub.values); err != nil {
+			return &common.ProtocolDataUnit{
+				FunctionCode: common.FuncCodeWriteFileRecord | 0x80,
+				Data:         []byte{common.ExceptionCodeIllegalDataAddress},
+			}
+		}
+	}
+
+	return &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeWriteFileRecord,
+		Data:         request.Data[:1+byteCount],
+	}
+}
+
+// handleMaskWriteRegister 处理屏蔽写寄存器请求 (功能码 0x16)
+func (s *RequestHandler) handleMaskWriteRegister(request *common.ProtocolDataUnit) *common.ProtocolDataUnit {
+	if len(request.Data) < 6 {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeMaskWriteRegister | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataAddress},
+		}
+	}
+
+	address := binary.BigEndian.Uint16(request.Data[0:2])
+	andMask := binary.BigEndian.Uint16(request.Data[2:4])
+	orMask := binary.BigEndian.Uint16(request.Data[4:6])
+
+	s.store.MaskWrite(PointTypeHoldingRegister, address, andMask, orMask)
+
+	return &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeMaskWriteRegister,
+		Data:         request.Data[0:6],
+	}
+}
+
+// handleReadWriteMultipleRegisters 处理读写多个寄存器请求 (功能码 0x17)，先写后读
+func (s *RequestHandler) handleReadWriteMultipleRegisters(request *common.ProtocolDataUnit) *common.ProtocolDataUnit {
+	if len(request.Data) < 9 {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReadWriteMultipleRegisters | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataAddress},
+		}
+	}
+
+	readAddress := binary.BigEndian.Uint16(request.Data[0:2])
+	readQuantity := binary.BigEndian.Uint16(request.Data[2:4])
+	writeAddress := binary.BigEndian.Uint16(request.Data[4:6])
+	writeQuantity := binary.BigEndian.Uint16(request.Data[6:8])
+	byteCount := int(request.Data[8])
+
+	if readQuantity < 1 || readQuantity > 125 || writeQuantity < 1 || writeQuantity > 121 {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReadWriteMultipleRegisters | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataValue},
+		}
+	}
+
+	if byteCount != int(writeQuantity*2) {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReadWriteMultipleRegisters | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataValue},
+		}
+	}
+
+	if len(request.Data) < 9+byteCount {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReadWriteMultipleRegisters | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataAddress},
+		}
+	}
+
+	values := make([]uint16, writeQuantity)
+	for i := range values {
+		values[i] = binary.BigEndian.Uint16(request.Data[9+i*2:])
+	}
+	results := s.store.ReadWriteRegisters(PointTypeHoldingRegister, readAddress, readQuantity, writeAddress, values)
+
+	responseData := make([]byte, 1+len(results)*2)
+	responseData[0] = byte(len(results) * 2)
+	for i, value := range results {
+		binary.BigEndian.PutUint16(responseData[1+i*2:], value)
+	}
+
+	return &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeReadWriteMultipleRegisters,
+		Data:         responseData,
+	}
+}
+
+// handleReadFIFOQueue 处理读取 FIFO 队列请求 (功能码 0x18)
+func (s *RequestHandler) handleReadFIFOQueue(request *common.ProtocolDataUnit) *common.ProtocolDataUnit {
+	if len(request.Data) < 2 {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReadFIFOQueue | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataAddress},
+		}
+	}
+
+	pointerAddress := binary.BigEndian.Uint16(request.Data[0:2])
+	queue, ok := s.store.GetFIFOQueue(pointerAddress)
+	if !ok {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReadFIFOQueue | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataAddress},
+		}
+	}
+
+	values := queue.Values()
+	if len(values) > FIFOQueueMaxCount {
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReadFIFOQueue | 0x80,
+			Data:         []byte{common.ExceptionCodeIllegalDataValue},
+		}
+	}
+
+	// 构建响应: [字节数(2)] [FIFO数量(2)] [数据...]
+	responseData := make([]byte, 4+len(values)*2)
+	binary.BigEndian.PutUint16(responseData[0:2], uint16(2+len(values)*2))
+	binary.BigEndian.PutUint16(responseData[2:4], uint16(len(values)))
+	for i, value := range values {
+		binary.BigEndian.PutUint16(responseData[4+i*2:], value)
+	}
+
+	return &common.ProtocolDataUnit{
+		FunctionCode: common.FuncCodeReadFIFOQueue,
+		Data:         responseData,
+	}
+}
+
 // handleReadDeviceIdentification 处理读取设备标识请求 (功能码 0x2B)
 func (s *RequestHandler) handleReadDeviceIdentification(request *common.ProtocolDataUnit) *common.ProtocolDataUnit {
-	if len(request.Data) < 2 {
+	// PDU 最大 253 字节，除去功能码后响应数据最多 252 字节
+	const maxResponseDataSize = 252
+
+	if len(request.Data) != 3 {
 		return &common.ProtocolDataUnit{
 			FunctionCode: common.FuncCodeReadDeviceIdentification | 0x80,
-			Data:         []byte{common.ExceptionCodeIllegalDataAddress},
+			Data:         []byte{common.ExceptionCodeIllegalDataValue},
 		}
 	}
 	if s.DeviceInfo == nil || s.DeviceInfo.Identification == nil {
@@ -361,69 +721,77 @@ func (s *RequestHandler) handleReadDeviceIdentification(request *common.Protocol
 	// 解析请求参数
 	meiType := request.Data[0]
 	readDeviceIDCode := request.Data[1]
+	objectId := request.Data[2]
 
 	// 检查MEI Type是否为设备标识请求
-	if meiType != 0x0E {
+	if meiType != common.MEITypeReadDeviceIdentification {
 		return &common.ProtocolDataUnit{
 			FunctionCode: common.FuncCodeReadDeviceIdentification | 0x80,
 			Data:         []byte{common.ExceptionCodeIllegalDataValue},
 		}
 	}
-	if readDeviceIDCode != 0x01 {
+	first, last, err := common.DeviceObjectRange(readDeviceIDCode)
+	if err != nil {
 		return &common.ProtocolDataUnit{
 			FunctionCode: common.FuncCodeReadDeviceIdentification | 0x80,
 			Data:         []byte{common.ExceptionCodeIllegalDataValue},
 		}
 	}
 
-	// 构建响应数据 MEI类型，设备对象ID，一致性等级，随后更多数据，随后对象ID, 对象数量
-	responseData := []byte{0x0E, 0x01, 0x01, 0x00, 0x00, 0x00}
-
-	itemCount := byte(0)
+	// 构建响应数据 MEI类型，读取码，一致性等级，后续标识，下一对象ID，对象数量，随后为对象ID、长度及数据
 	deviceID := s.DeviceInfo.Identification
-	if deviceID.VendorName != "" {
-		value := []byte(deviceID.VendorName)
-		responseData = append(responseData, 0x00, byte(len(value)))
-		responseData = append(responseData, value...)
-		itemCount++
-	}
-	if deviceID.ProductCode != "" {
-		value := []byte(deviceID.ProductCode)
-		responseData = append(responseData, 0x01, byte(len(value)))
-		responseData = append(responseData, value...)
-		itemCount++
-	}
-	if deviceID.ProductName != "" {
-		value := []byte(deviceID.ProductName)
-		responseData = append(responseData, 0x02, byte(len(value)))
-		responseData = append(responseData, value...)
-		itemCount++
-	}
-	if deviceID.VendorUrl != "" {
-		value := []byte(deviceID.VendorUrl)
-		responseData = append(responseData, 0x03, byte(len(value)))
-		responseData = append(responseData, value...)
-		itemCount++
-	}
-	if deviceID.ProductName != "" {
-		value := []byte(deviceID.ProductName)
-		responseData = append(responseData, 0x04, byte(len(value)))
+	responseData := []byte{meiType, readDeviceIDCode, deviceID.Conformity(), 0x00, 0x00, 0x00}
+
+	// 读取单个对象，对象不存在时返回非法数据地址
+	if readDeviceIDCode == common.ReadDeviceIDCodeSpecific {
+		value, ok := deviceID.Object(objectId)
+		if !ok {
+			return &common.ProtocolDataUnit{
+				FunctionCode: common.FuncCodeReadDeviceIdentification | 0x80,
+				Data:         []byte{common.ExceptionCodeIllegalDataAddress},
+			}
+		}
+		if len(responseData)+2+len(value) > maxResponseDataSize {
+			return &common.ProtocolDataUnit{
+				FunctionCode: common.FuncCodeReadDeviceIdentification | 0x80,
+				Data:         []byte{common.ExceptionCodeServerDeviceFailure},
+			}
+		}
+		responseData[5] = 1
+		responseData = append(responseData, objectId, byte(len(value)))
 		responseData = append(responseData, value...)
-		itemCount++
+		return &common.ProtocolDataUnit{
+			FunctionCode: common.FuncCodeReadDeviceIdentification,
+			Data:         responseData,
+		}
 	}
-	if deviceID.ModelName != "" {
-		value := []byte(deviceID.ModelName)
-		responseData = append(responseData, 0x05, byte(len(value)))
-		responseData = append(responseData, value...)
-		itemCount++
+
+	// 流式读取，对象 ID 不存在或不属于该类别时从第一个对象开始
+	if _, ok := deviceID.Object(objectId); !ok || objectId < first || objectId > last {
+		objectId = first
 	}
-	if deviceID.UserApplicationName != "" {
-		value := []byte(deviceID.UserApplicationName)
-		responseData = append(responseData, 0x06, byte(len(value)))
+	for _, id := range deviceID.ObjectIds() {
+		if id < objectId || id > last {
+			continue
+		}
+		value, _ := deviceID.Object(id)
+		if len(responseData)+2+len(value) > maxResponseDataSize {
+			// 单个对象超过响应长度时无法继续读取
+			if responseData[5] == 0 {
+				return &common.ProtocolDataUnit{
+					FunctionCode: common.FuncCodeReadDeviceIdentification | 0x80,
+					Data:         []byte{common.ExceptionCodeServerDeviceFailure},
+				}
+			}
+			// 剩余对象由下一次请求从该对象开始读取
+			responseData[3] = 0xFF
+			responseData[4] = id
+			break
+		}
+		responseData = append(responseData, id, byte(len(value)))
 		responseData = append(responseData, value...)
-		itemCount++
+		responseData[5]++
 	}
-	responseData[5] = itemCount
 
 	return &common.ProtocolDataUnit{
 		FunctionCode: common.FuncCodeReadDeviceIdentification,
@@ -0,0 +1,7 @@
+package slave
+
+// NewModbusRTUSlave 创建串口 RTU 从站，通过 common.SerialServer 接入总线，
+// 同一总线上的多个从站使用不同的 slaveId 注册到同一个 SerialServer
+func NewModbusRTUSlave(slaveId uint8, deviceInfo *DeviceInfo, store *MemoryDataStore) *ModbusSlave {
+	return NewModbusRTUOverTCPSlave(slaveId, deviceInfo, store)
+}
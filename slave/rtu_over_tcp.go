@@ -1,9 +1,13 @@
 package slave
 
-import "github.com/veryinf/modbus-kit/common"
+import (
+	"context"
+
+	"github.com/veryinf/modbus-kit/common"
+)
 
 func NewModbusRTUOverTCPSlave(slaveId uint8, deviceInfo *DeviceInfo, store *MemoryDataStore) *ModbusSlave {
-	transport := &TCPTransport{}
+	transport := &RTUOverTCPTransport{}
 	transport.RequestHandler.store = store
 	transport.RequestHandler.DeviceInfo = deviceInfo
 	slaveInfo := common.ModbusDevice{
@@ -21,10 +25,15 @@ type RTUOverTCPTransport struct {
 func (t *RTUOverTCPTransport) Send(requestData []byte) (responseData []byte, err error) {
 	frame, err := common.NewRTUFrameFromBytes(requestData)
 	if err != nil {
+		t.countBusMessage(true)
 		return nil, err
 	}
+	t.countBusMessage(false)
+	if frame.SlaveId == common.BroadcastSlaveId {
+		return nil, t.HandleBroadcast(context.Background(), frame.PDU)
+	}
 	response, err := t.HandleRequest(frame.PDU)
-	if err != nil {
+	if err != nil || response == nil {
 		return nil, err
 	}
 	frame.PDU = response
@@ -0,0 +1,7 @@
+package slave
+
+// NewModbusRTUOverUDPSlave 创建 RTU over UDP 从站，注册到以 udp:// 地址运行的 common.NetServer，
+// 每个数据报作为一个 RTU 帧处理，可用于在本地模拟以 UDP 透传 RTU 帧的 DTU
+func NewModbusRTUOverUDPSlave(slaveId uint8, deviceInfo *DeviceInfo, store *MemoryDataStore) *ModbusSlave {
+	return NewModbusRTUOverTCPSlave(slaveId, deviceInfo, store)
+}
@@ -3,6 +3,8 @@ package slave
 import (
 	"reflect"
 	"sync"
+
+	"github.com/veryinf/modbus-kit/common"
 )
 
 type PointType string
@@ -12,12 +14,14 @@ const (
 	PointTypeDiscreteInput   PointType = "discrete_input"
 	PointTypeHoldingRegister PointType = "holding_register"
 	PointTypeInputRegister   PointType = "input_register"
+	PointTypeEnronRegister   PointType = "enron_register" // Enron/Daniel 扩展的 32 位保持寄存器
 )
 
 // Point 类型
 type Point struct {
 	Address uint16 // 地址
 	Value   uint16
+	Value32 uint32 // Enron 32 位寄存器的值，其它类型为 0
 	Type    PointType
 }
 
@@ -31,7 +35,12 @@ type MemoryDataStore struct {
 	discreteInputs      map[uint16]bool
 	holdingRegisters    map[uint16]uint16
 	inputRegisters      map[uint16]uint16
+	fifoQueues          map[uint16]*FIFOQueue
+	files               map[uint16][]uint16
+	enronLayout         *common.EnronLayout
+	enronRegisters      map[uint16]uint32
 	eventWriteCallbacks []PointWriteCallback // 事件回调列表
+	bindings            []*Binding           // 绑定的结构体
 }
 
 // NewMemoryDataStore 创建新的内存数据存储
@@ -41,6 +50,9 @@ func NewMemoryDataStore() *MemoryDataStore {
 		discreteInputs:      make(map[uint16]bool),
 		holdingRegisters:    make(map[uint16]uint16),
 		inputRegisters:      make(map[uint16]uint16),
+		fifoQueues:          make(map[uint16]*FIFOQueue),
+		files:               make(map[uint16][]uint16),
+		enronRegisters:      make(map[uint16]uint32),
 		eventWriteCallbacks: make([]PointWriteCallback, 0),
 	}
 }
@@ -66,27 +78,35 @@ func (m *MemoryDataStore) RemoveWriteEventCallback(callback PointWriteCallback)
 	}
 }
 
-// triggerWriteEvent 触发事件回调
+// triggerWriteEvent 触发事件回调，并更新绑定的结构体
 func (m *MemoryDataStore) triggerWriteEvent(address uint16, value uint16, valueType PointType) {
+	m.triggerPointEvent(Point{
+		Address: address,
+		Value:   value,
+		Type:    valueType,
+	})
+}
+
+// triggerPointEvent 以 event 触发事件回调，并更新绑定的结构体
+func (m *MemoryDataStore) triggerPointEvent(event Point) {
 	m.mu.RLock()
 
-	// 检查是否有回调函数
-	if len(m.eventWriteCallbacks) == 0 {
+	// 检查是否有回调函数或绑定的结构体
+	if len(m.eventWriteCallbacks) == 0 && len(m.bindings) == 0 {
 		m.mu.RUnlock()
 		return
 	}
 
-	event := Point{
-		Address: address,
-		Value:   value,
-		Type:    valueType,
-	}
-
 	// 创建回调函数副本以避免在锁内执行回调
 	callbacks := make([]PointWriteCallback, len(m.eventWriteCallbacks))
 	copy(callbacks, m.eventWriteCallbacks)
+	bindings := make([]*Binding, len(m.bindings))
+	copy(bindings, m.bindings)
 	m.mu.RUnlock()
 
+	for _, binding := range bindings {
+		binding.update(event)
+	}
 	for _, callback := range callbacks {
 		callback(event)
 	}
@@ -140,6 +160,72 @@ func (m *MemoryDataStore) Write(pointType PointType, address uint16, value uint1
 	m.triggerWriteEvent(address, value, pointType)
 }
 
+// MaskWrite 在锁内完成寄存器的读-改-写: (当前值 AND andMask) OR (orMask AND (NOT andMask))，返回写入后的值
+func (m *MemoryDataStore) MaskWrite(pointType PointType, address uint16, andMask uint16, orMask uint16) uint16 {
+	m.mu.Lock()
+
+	var value uint16
+	switch pointType {
+	case PointTypeHoldingRegister:
+		value = (m.holdingRegisters[address] & andMask) | (orMask &^ andMask)
+		m.holdingRegisters[address] = value
+	case PointTypeInputRegister:
+		value = (m.inputRegisters[address] & andMask) | (orMask &^ andMask)
+		m.inputRegisters[address] = value
+	default:
+		m.mu.Unlock()
+		return 0
+	}
+	m.mu.Unlock()
+	m.triggerWriteEvent(address, value, pointType)
+	return value
+}
+
+// ReadWriteRegisters 在同一把锁内先写入 values 再读取 readQuantity 个寄存器，保证读写的原子性
+func (m *MemoryDataStore) ReadWriteRegisters(pointType PointType, readAddress uint16, readQuantity uint16, writeAddress uint16, values []uint16) []uint16 {
+	registers := m.registers(pointType)
+	if registers == nil {
+		return make([]uint16, readQuantity)
+	}
+
+	m.mu.Lock()
+	for i, value := range values {
+		registers[writeAddress+uint16(i)] = value
+	}
+	result := make([]uint16, readQuantity)
+	for i := uint16(0); i < readQuantity; i++ {
+		result[i] = registers[readAddress+i]
+	}
+	m.mu.Unlock()
+
+	for i, value := range values {
+		m.triggerWriteEvent(writeAddress+uint16(i), value, pointType)
+	}
+	return result
+}
+
+// AddFIFOQueue 获取指定指针地址的 FIFO 队列，不存在时创建
+func (m *MemoryDataStore) AddFIFOQueue(pointerAddress uint16) *FIFOQueue {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	queue, ok := m.fifoQueues[pointerAddress]
+	if !ok {
+		queue = NewFIFOQueue()
+		m.fifoQueues[pointerAddress] = queue
+	}
+	return queue
+}
+
+// GetFIFOQueue 获取指定指针地址的 FIFO 队列
+func (m *MemoryDataStore) GetFIFOQueue(pointerAddress uint16) (*FIFOQueue, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	queue, ok := m.fifoQueues[pointerAddress]
+	return queue, ok
+}
+
 func (m *MemoryDataStore) GetAllPoints() []Point {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -182,6 +268,14 @@ func (m *MemoryDataStore) GetAllPoints() []Point {
 			Type:    PointTypeInputRegister,
 		})
 	}
+	for address, value := range m.enronRegisters {
+		points = append(points, Point{
+			Address: address,
+			Value:   uint16(value),
+			Value32: value,
+			Type:    PointTypeEnronRegister,
+		})
+	}
 
 	return points
 }
@@ -1,6 +1,10 @@
 package slave
 
-import "github.com/veryinf/modbus-kit/common"
+import (
+	"context"
+
+	"github.com/veryinf/modbus-kit/common"
+)
 
 func NewModbusTCPSlave(slaveId uint8, deviceInfo *DeviceInfo, store *MemoryDataStore) *ModbusSlave {
 	transport := &TCPTransport{}
@@ -19,12 +23,19 @@ type TCPTransport struct {
 }
 
 func (t *TCPTransport) Send(requestData []byte) (responseData []byte, err error) {
+	return t.SendContext(context.Background(), requestData)
+}
+
+// SendContext 处理请求，ctx 传递给请求处理函数，如 TLS 连接中携带客户端的 Modbus Role
+func (t *TCPTransport) SendContext(ctx context.Context, requestData []byte) (responseData []byte, err error) {
 	frame, err := common.NewMBAPFrameFromBytes(requestData)
 	if err != nil {
 		return nil, err
 	}
-	response, err := t.HandleRequest(frame.PDU)
-	if err != nil {
+	// Modbus/TCP 中单元 ID 0 用于直接访问服务器，不作为广播处理
+	t.countBusMessage(false)
+	response, err := t.HandleRequestContext(ctx, frame.PDU)
+	if err != nil || response == nil {
 		return nil, err
 	}
 	frame.PDU = response
@@ -0,0 +1,209 @@
+package slave
+
+import (
+	"encoding/binary"
+
+	"github.com/veryinf/modbus-kit/common"
+)
+
+// ReadRegisters 在同一把锁内读取连续的寄存器
+func (m *MemoryDataStore) ReadRegisters(pointType PointType, address uint16, quantity uint16) []uint16 {
+	values := make([]uint16, quantity)
+	m.ReadRegistersInto(pointType, address, values)
+	return values
+}
+
+// ReadRegistersInto 在同一把锁内读取 len(dst) 个连续的寄存器到 dst
+func (m *MemoryDataStore) ReadRegistersInto(pointType PointType, address uint16, dst []uint16) {
+	registers := m.registers(pointType)
+	if registers == nil {
+		clear(dst)
+		return
+	}
+	m.mu.RLock()
+	for i := range dst {
+		dst[i] = registers[address+uint16(i)]
+	}
+	m.mu.RUnlock()
+}
+
+// AppendRegisterBytes 在同一把锁内读取 quantity 个连续的寄存器，按大端编码追加到 dst，dst 容量足够时不分配内存
+func (m *MemoryDataStore) AppendRegisterBytes(dst []byte, pointType PointType, address uint16, quantity uint16) []byte {
+	registers := m.registers(pointType)
+	m.mu.RLock()
+	for i := uint16(0); i < quantity; i++ {
+		// 非寄存器类型的 registers 为 nil，读取结果为 0
+		dst = binary.BigEndian.AppendUint16(dst, registers[address+i])
+	}
+	m.mu.RUnlock()
+	return dst
+}
+
+// WriteRegisters 在同一把锁内写入连续的寄存器，写入后逐个触发写事件
+func (m *MemoryDataStore) WriteRegisters(pointType PointType, address uint16, values []uint16) {
+	registers := m.registers(pointType)
+	if registers == nil {
+		return
+	}
+	m.mu.Lock()
+	for i, value := range values {
+		registers[address+uint16(i)] = value
+	}
+	m.mu.Unlock()
+	for i, value := range values {
+		m.triggerWriteEvent(address+uint16(i), value, pointType)
+	}
+}
+
+// WriteRegisterBytes 在同一把锁内写入大端编码的连续寄存器，写入后逐个触发写事件
+func (m *MemoryDataStore) WriteRegisterBytes(pointType PointType, address uint16, data []byte) {
+	registers := m.registers(pointType)
+	if registers == nil {
+		return
+	}
+	quantity := uint16(len(data) / 2)
+	m.mu.Lock()
+	for i := uint16(0); i < quantity; i++ {
+		registers[address+i] = binary.BigEndian.Uint16(data[i*2:])
+	}
+	m.mu.Unlock()
+	for i := uint16(0); i < quantity; i++ {
+		m.triggerWriteEvent(address+i, binary.BigEndian.Uint16(data[i*2:]), pointType)
+	}
+}
+
+// ReadBits 在同一把锁内读取连续的线圈或离散输入
+func (m *MemoryDataStore) ReadBits(pointType PointType, address uint16, quantity uint16) *common.BitVector {
+	bits := m.bits(pointType)
+	values := common.NewBitVector(uint(quantity))
+	m.mu.RLock()
+	for i := uint16(0); i < quantity; i++ {
+		// 非位类型的 bits 为 nil，读取结果为 false
+		if bits[address+i] {
+			values.Set(uint(i), true)
+		}
+	}
+	m.mu.RUnlock()
+	return values
+}
+
+// WriteBits 在同一把锁内写入连续的线圈或离散输入，写入后逐个触发写事件
+func (m *MemoryDataStore) WriteBits(pointType PointType, address uint16, values *common.BitVector) {
+	bits := m.bits(pointType)
+	if bits == nil {
+		return
+	}
+	m.mu.Lock()
+	for i := uint(0); i < values.Size(); i++ {
+		bits[address+uint16(i)] = values.Get(i)
+	}
+	m.mu.Unlock()
+	for i := uint(0); i < values.Size(); i++ {
+		var value uint16
+		if values.Get(i) {
+			value = 1
+		}
+		m.triggerWriteEvent(address+uint16(i), value, pointType)
+	}
+}
+
+// bits 返回位类型对应的存储，其它类型返回 nil
+func (m *MemoryDataStore) bits(pointType PointType) map[uint16]bool {
+	switch pointType {
+	case PointTypeCoil:
+		return m.coils
+	case PointTypeDiscreteInput:
+		return m.discreteInputs
+	}
+	return nil
+}
+
+// registers 返回寄存器类型对应的存储，其它类型返回 nil
+func (m *MemoryDataStore) registers(pointType PointType) map[uint16]uint16 {
+	switch pointType {
+	case PointTypeHoldingRegister:
+		return m.holdingRegisters
+	case PointTypeInputRegister:
+		return m.inputRegisters
+	}
+	return nil
+}
+
+// ReadInt16 按字节序读取从 address 开始的 int16
+func (m *MemoryDataStore) ReadInt16(pointType PointType, address uint16, order common.ByteOrder) int16 {
+	return readValue[int16](m, pointType, address, order)
+}
+
+// WriteInt16 按字节序将 int16 写入从 address 开始的寄存器
+func (m *MemoryDataStore) WriteInt16(pointType PointType, address uint16, value int16, order common.ByteOrder) {
+	writeValue(m, pointType, address, value, order)
+}
+
+// ReadUint32 按字节序读取从 address 开始的 uint32
+func (m *MemoryDataStore) ReadUint32(pointType PointType, address uint16, order common.ByteOrder) uint32 {
+	return readValue[uint32](m, pointType, address, order)
+}
+
+// WriteUint32 按字节序将 uint32 写入从 address 开始的寄存器
+func (m *MemoryDataStore) WriteUint32(pointType PointType, address uint16, value uint32, order common.ByteOrder) {
+	writeValue(m, pointType, address, value, order)
+}
+
+// ReadInt32 按字节序读取从 address 开始的 int32
+func (m *MemoryDataStore) ReadInt32(pointType PointType, address uint16, order common.ByteOrder) int32 {
+	return readValue[int32](m, pointType, address, order)
+}
+
+// WriteInt32 按字节序将 int32 写入从 address 开始的寄存器
+func (m *MemoryDataStore) WriteInt32(pointType PointType, address uint16, value int32, order common.ByteOrder) {
+	writeValue(m, pointType, address, value, order)
+}
+
+// ReadFloat32 按字节序读取从 address 开始的 float32
+func (m *MemoryDataStore) ReadFloat32(pointType PointType, address uint16, order common.ByteOrder) float32 {
+	return readValue[float32](m, pointType, address, order)
+}
+
+// WriteFloat32 按字节序将 float32 写入从 address 开始的寄存器
+func (m *MemoryDataStore) WriteFloat32(pointType PointType, address uint16, value float32, order common.ByteOrder) {
+	writeValue(m, pointType, address, value, order)
+}
+
+// ReadUint64 按字节序读取从 address 开始的 uint64
+func (m *MemoryDataStore) ReadUint64(pointType PointType, address uint16, order common.ByteOrder) uint64 {
+	return readValue[uint64](m, pointType, address, order)
+}
+
+// WriteUint64 按字节序将 uint64 写入从 address 开始的寄存器
+func (m *MemoryDataStore) WriteUint64(pointType PointType, address uint16, value uint64, order common.ByteOrder) {
+	writeValue(m, pointType, address, value, order)
+}
+
+// ReadInt64 按字节序读取从 address 开始的 int64
+func (m *MemoryDataStore) ReadInt64(pointType PointType, address uint16, order common.ByteOrder) int64 {
+	return readValue[int64](m, pointType, address, order)
+}
+
+// WriteInt64 按字节序将 int64 写入从 address 开始的寄存器
+func (m *MemoryDataStore) WriteInt64(pointType PointType, address uint16, value int64, order common.ByteOrder) {
+	writeValue(m, pointType, address, value, order)
+}
+
+// ReadFloat64 按字节序读取从 address 开始的 float64
+func (m *MemoryDataStore) ReadFloat64(pointType PointType, address uint16, order common.ByteOrder) float64 {
+	return readValue[float64](m, pointType, address, order)
+}
+
+// WriteFloat64 按字节序将 float64 写入从 address 开始的寄存器
+func (m *MemoryDataStore) WriteFloat64(pointType PointType, address uint16, value float64, order common.ByteOrder) {
+	writeValue(m, pointType, address, value, order)
+}
+
+func readValue[T common.Number](m *MemoryDataStore, pointType PointType, address uint16, order common.ByteOrder) T {
+	values, _ := common.DecodeWords[T](m.ReadRegisters(pointType, address, uint16(common.RegisterCount[T]())), order)
+	return values[0]
+}
+
+func writeValue[T common.Number](m *MemoryDataStore, pointType PointType, address uint16, value T, order common.ByteOrder) {
+	m.WriteRegisters(pointType, address, common.EncodeWords([]T{value}, order))
+}